@@ -25,11 +25,15 @@ type Subscription struct {
 }
 
 type Profile struct {
-	ID             string `json:"id"`
-	Name           string `json:"name"`
-	Key            string `json:"key"`
-	SubscriptionID string `json:"subscription_id"`
-	CreatedAt      int64  `json:"created_at"`
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Key            string          `json:"key"`
+	Outbound       *Outbound       `json:"outbound,omitempty"`
+	Options        *ProfileOptions `json:"options,omitempty"`
+	SubscriptionID string          `json:"subscription_id"`
+	CreatedAt      int64           `json:"created_at"`
+	Tags           []string        `json:"tags,omitempty"`
+	DetourID string `json:"detour_id,omitempty"`
 }
 
 type Settings struct {
@@ -37,16 +41,30 @@ type Settings struct {
 	RunMode       string     `json:"run_mode"`
 	MixedPort     int        `json:"mixed_port"`
 	UserRules     []UserRule `json:"user_rules"`
-	RuDomains     []string   `json:"ru_domains"`
+	RuDomains     []string   `json:"ru_domains,omitempty"`
 	AutoConnect   bool       `json:"auto_connect"`
 	LastProfileID string     `json:"last_profile_id"`
+	ConfigOverlay string `json:"config_overlay"`
+	ConnectMode string        `json:"connect_mode"`
+	Failover    FailoverGroup `json:"failover"`
+	DNS         DNSSettings   `json:"dns"`
+	IPv6 string      `json:"ipv6"`
+	Tun  TunSettings `json:"tun"`
+	RuleSets []RuleSet `json:"rule_sets"`
+	Regions       []string `json:"regions"`
+	DirectDomains []string `json:"direct_domains"`
+	RegionDNS bool `json:"region_dns,omitempty"`
 }
 
 type UserRule struct {
-	ID       string `json:"id"`
-	Type     string `json:"type"`
-	Value    string `json:"value"`
-	Outbound string `json:"outbound"`
+	ID     string   `json:"id"`
+	Type   string   `json:"type"`
+	Values []string `json:"values,omitempty"`
+	// Value is the single value of rules saved by older versions.
+	Value      string          `json:"value,omitempty"`
+	Mode       string          `json:"mode,omitempty"`
+	Conditions []RuleCondition `json:"conditions,omitempty"`
+	Outbound   string          `json:"outbound"`
 }
 
 type App struct {
@@ -63,10 +81,9 @@ type App struct {
 
 	logBuffer []string
 	logLock   sync.Mutex
-}
 
-var defaultRuDomains = []string{
-	".ru", ".rf", ".xn--p1ai",
+	ruleSetLock sync.Mutex
+	settingsLock sync.Mutex
 }
 
 func NewApp() *App {
@@ -76,9 +93,13 @@ func NewApp() *App {
 		Settings: Settings{
 			RoutingMode: "smart",
 			RunMode:     "tun",
+			ConnectMode: "profile",
 			MixedPort:   2080,
 			UserRules:   []UserRule{},
-			RuDomains:   defaultRuDomains,
+			RuleSets:    []RuleSet{},
+			Regions:     []string{"ru"},
+			DNS:         defaultDNSSettings(),
+			IPv6:        "off",
 		},
 		isQuitting: false,
 		logBuffer:  make([]string, 0, 100),
@@ -116,7 +137,8 @@ func (a *App) startup(ctx context.Context) {
 	a.LoadSettings()
 	a.LoadProfiles()
 	a.LoadSubscriptions()
-	
+	a.startRuleSetUpdater()
+
 	a.platformInit()
 
 	go func() {
@@ -129,7 +151,7 @@ func (a *App) startup(ctx context.Context) {
 			a.log("Failed to install core: " + err.Error())
 			wailsRuntime.EventsEmit(a.ctx, "error", "Core Install Error")
 		} else {
-			if a.Settings.AutoConnect && a.Settings.LastProfileID != "" {
+			if a.settingsSnapshot().AutoConnect {
 				a.connectLastProfile()
 			}
 		}
@@ -137,9 +159,19 @@ func (a *App) startup(ctx context.Context) {
 }
 
 func (a *App) connectLastProfile() {
+	settings := a.settingsSnapshot()
+	if settings.ConnectMode == "failover" {
+		a.log("Auto-connecting (failover)...")
+		time.Sleep(1 * time.Second)
+		if res := a.StartFailover(); res != "Connected" {
+			a.log("Auto-connect ERROR: " + res)
+		}
+		return
+	}
+
 	var targetLink string
 	for _, p := range a.Profiles {
-		if p.ID == a.Settings.LastProfileID {
+		if p.ID == settings.LastProfileID {
 			targetLink = p.Key
 			break
 		}
@@ -169,5 +201,7 @@ func (a *App) getAppDataDir() string {
 
 func (a *App) getProfilesPath() string { return filepath.Join(a.getAppDataDir(), "profiles.json") }
 func (a *App) getSettingsPath() string { return filepath.Join(a.getAppDataDir(), "settings.json") }
-func (a *App) getGeoIpPath() string    { return filepath.Join(a.getAppDataDir(), "geoip.dat") }
-func (a *App) getSrsPath() string      { return filepath.Join(a.getAppDataDir(), "geoip-ru.srs") }
\ No newline at end of file
+func (a *App) getRuleSetDir() string   { return filepath.Join(a.getAppDataDir(), "rule-sets") }
+func (a *App) getSrsPath(tag string) string {
+	return filepath.Join(a.getRuleSetDir(), tag+".srs")
+}
\ No newline at end of file
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (a *App) findProfile(id string) (Profile, bool) {
+	for _, p := range a.Profiles {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// detourChain returns the profiles in dial order, e.g. [exit, entry].
+func (a *App) detourChain(p Profile) ([]Profile, error) {
+	chain := []Profile{p}
+	seen := map[string]bool{p.ID: true}
+	for p.DetourID != "" {
+		next, ok := a.findProfile(p.DetourID)
+		if !ok {
+			return nil, fmt.Errorf("upstream of %s not found", p.Name)
+		}
+		chain = append(chain, next)
+		if seen[next.ID] {
+			return nil, fmt.Errorf("detour cycle: %s", chainNames(chain))
+		}
+		seen[next.ID] = true
+		p = next
+	}
+	return chain, nil
+}
+
+func chainNames(chain []Profile) string {
+	names := make([]string, len(chain))
+	for i, p := range chain {
+		names[i] = p.Name
+	}
+	return strings.Join(names, " → ")
+}
+
+func (a *App) SetProfileDetour(id, detourID string) string {
+	index := -1
+	for i, p := range a.Profiles {
+		if p.ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return "Profile not found"
+	}
+
+	if detourID != "" {
+		if detourID == id {
+			return "A profile cannot detour through itself"
+		}
+		upstream, ok := a.findProfile(detourID)
+		if !ok {
+			return "Upstream profile not found"
+		}
+		chain, err := a.detourChain(upstream)
+		if err != nil {
+			return err.Error()
+		}
+		for i, p := range chain {
+			if p.ID == id {
+				loop := append([]Profile{a.Profiles[index]}, chain[:i+1]...)
+				return "Detour cycle: " + chainNames(loop)
+			}
+		}
+	}
+
+	a.Profiles[index].DetourID = detourID
+	if err := a.SaveProfiles(); err != nil {
+		return "Save failed: " + err.Error()
+	}
+	return "OK"
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func chainApp(detours map[string]string) *App {
+	a := NewApp()
+	for _, id := range []string{"a", "b", "c"} {
+		a.Profiles = append(a.Profiles, Profile{ID: id, Name: strings.ToUpper(id), DetourID: detours[id]})
+	}
+	return a
+}
+
+func TestDetourChain(t *testing.T) {
+	a := chainApp(map[string]string{"a": "b", "b": "c"})
+	p, _ := a.findProfile("a")
+	chain, err := a.detourChain(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := chainNames(chain); got != "A → B → C" {
+		t.Errorf("chain %s, want A → B → C", got)
+	}
+
+	a = chainApp(map[string]string{"a": "missing"})
+	p, _ = a.findProfile("a")
+	if _, err := a.detourChain(p); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("missing upstream: %v", err)
+	}
+
+	a = chainApp(map[string]string{"a": "b", "b": "c", "c": "b"})
+	p, _ = a.findProfile("a")
+	if _, err := a.detourChain(p); err == nil || !strings.Contains(err.Error(), "B → C → B") {
+		t.Errorf("cycle: %v", err)
+	}
+}
+
+func TestSetProfileDetourRejects(t *testing.T) {
+	a := chainApp(map[string]string{"b": "c"})
+	for name, c := range map[string]struct{ id, detour, want string }{
+		"self":    {"a", "a", "itself"},
+		"missing": {"a", "x", "not found"},
+		"cycle":   {"c", "b", "Detour cycle: C → B → C"},
+		"unknown": {"x", "a", "Profile not found"},
+	} {
+		if got := a.SetProfileDetour(c.id, c.detour); !strings.Contains(got, c.want) {
+			t.Errorf("%s: got %q, want %q", name, got, c.want)
+		}
+	}
+}
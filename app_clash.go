@@ -0,0 +1,303 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ports and bandwidth are strings because providers write them quoted and
+// unquoted.
+type clashDocument struct {
+	Proxies []clashProxy `yaml:"proxies"`
+}
+
+type clashProxy struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Server   string `yaml:"server"`
+	Port     string `yaml:"port"`
+	UUID     string `yaml:"uuid"`
+	Password string `yaml:"password"`
+	Cipher   string `yaml:"cipher"`
+	AlterID  string `yaml:"alterId"`
+	Flow     string `yaml:"flow"`
+
+	TLS               bool     `yaml:"tls"`
+	ServerName        string   `yaml:"servername"`
+	SNI               string   `yaml:"sni"`
+	SkipCertVerify    bool     `yaml:"skip-cert-verify"`
+	ClientFingerprint string   `yaml:"client-fingerprint"`
+	ALPN              []string `yaml:"alpn"`
+	RealityOpts       struct {
+		PublicKey string `yaml:"public-key"`
+		ShortID   string `yaml:"short-id"`
+	} `yaml:"reality-opts"`
+
+	Network string `yaml:"network"`
+	WSOpts  struct {
+		Path                string            `yaml:"path"`
+		Headers             map[string]string `yaml:"headers"`
+		MaxEarlyData        int               `yaml:"max-early-data"`
+		EarlyDataHeaderName string            `yaml:"early-data-header-name"`
+		HTTPUpgrade         bool              `yaml:"v2ray-http-upgrade"`
+	} `yaml:"ws-opts"`
+	GRPCOpts struct {
+		ServiceName string `yaml:"grpc-service-name"`
+	} `yaml:"grpc-opts"`
+	HTTPOpts struct {
+		Path    []string            `yaml:"path"`
+		Headers map[string][]string `yaml:"headers"`
+	} `yaml:"http-opts"`
+	H2Opts struct {
+		Host []string `yaml:"host"`
+		Path string   `yaml:"path"`
+	} `yaml:"h2-opts"`
+
+	Plugin     string                 `yaml:"plugin"`
+	PluginOpts map[string]interface{} `yaml:"plugin-opts"`
+
+	Ports        string `yaml:"ports"`
+	Obfs         string `yaml:"obfs"`
+	ObfsPassword string `yaml:"obfs-password"`
+	Up           string `yaml:"up"`
+	Down         string `yaml:"down"`
+
+	CongestionController string `yaml:"congestion-controller"`
+	UDPRelayMode         string `yaml:"udp-relay-mode"`
+	ReduceRTT            bool   `yaml:"reduce-rtt"`
+	DisableSNI           bool   `yaml:"disable-sni"`
+}
+
+var clashProxiesKey = regexp.MustCompile(`(?m)^proxies:`)
+
+func isClashConfig(content string) bool {
+	return clashProxiesKey.MatchString(content)
+}
+
+var clashCiphers = map[string]string{
+	"chacha20-poly1305":      "chacha20-ietf-poly1305",
+	"xchacha20-poly1305":     "xchacha20-ietf-poly1305",
+	"aead_aes_128_gcm":       "aes-128-gcm",
+	"aead_aes_192_gcm":       "aes-192-gcm",
+	"aead_aes_256_gcm":       "aes-256-gcm",
+	"aead_chacha20_poly1305": "chacha20-ietf-poly1305",
+	"dummy":                  "none",
+}
+
+func clashLinks(content string) (links, skipped []string) {
+	var doc clashDocument
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, []string{"clash config: " + err.Error()}
+	}
+
+	for _, p := range doc.Proxies {
+		link, err := p.shareLink()
+		if err != nil {
+			skipped = append(skipped, p.Name+": "+err.Error())
+			continue
+		}
+		links = append(links, link)
+	}
+	return links, skipped
+}
+
+func (p *clashProxy) shareLink() (string, error) {
+	ob, err := p.outbound()
+	if err != nil {
+		return "", err
+	}
+	if err := ob.validate(); err != nil {
+		return "", err
+	}
+	return shareLink(ob, p.Name)
+}
+
+func (p *clashProxy) outbound() (*Outbound, error) {
+	port, _ := strconv.Atoi(p.Port)
+	ob := &Outbound{Server: p.Server, Port: port}
+
+	var err error
+
+	switch p.Type {
+	case "vless":
+		ob.Protocol = "vless"
+		ob.Credentials = Credentials{UUID: p.UUID, Flow: p.Flow}
+		ob.TLS = p.tlsOptions(p.TLS, p.ServerName)
+		if ob.Transport, err = p.transportOptions(); err != nil {
+			return nil, err
+		}
+	case "vmess":
+		ob.Protocol = "vmess"
+		alterID, _ := strconv.Atoi(p.AlterID)
+		method := p.Cipher
+		if method == "" {
+			method = "auto"
+		}
+		ob.Credentials = Credentials{UUID: p.UUID, Method: method, AlterID: alterID}
+		ob.TLS = p.tlsOptions(p.TLS, p.ServerName)
+		if ob.Transport, err = p.transportOptions(); err != nil {
+			return nil, err
+		}
+	case "trojan":
+		ob.Protocol = "trojan"
+		ob.Credentials = Credentials{Password: p.Password}
+		ob.TLS = p.tlsOptions(true, p.SNI)
+		if ob.Transport, err = p.transportOptions(); err != nil {
+			return nil, err
+		}
+	case "ss":
+		ob.Protocol = "shadowsocks"
+		method := strings.ToLower(strings.TrimSpace(p.Cipher))
+		if mapped, ok := clashCiphers[method]; ok {
+			method = mapped
+		}
+		ob.Credentials = Credentials{Method: method, Password: p.Password}
+		if p.Plugin != "" {
+			ss, err := p.shadowsocksPlugin()
+			if err != nil {
+				return nil, err
+			}
+			ob.Shadowsocks = ss
+		}
+	case "hysteria2":
+		ob.Protocol = "hysteria2"
+		ob.Credentials = Credentials{Password: p.Password}
+		hy := &Hysteria2Options{
+			UpMbps:   clashBandwidth(p.Up),
+			DownMbps: clashBandwidth(p.Down),
+		}
+		for _, r := range splitList(p.Ports) {
+			from, to, ok := strings.Cut(r, "-")
+			if !ok {
+				to = from
+			}
+			hy.Ports = append(hy.Ports, from+":"+to)
+		}
+		if p.Obfs != "" {
+			hy.ObfsType = p.Obfs
+			hy.ObfsPassword = p.ObfsPassword
+		}
+		ob.Hysteria2 = hy
+		ob.TLS = &TLSOptions{ServerName: p.SNI, Insecure: p.SkipCertVerify, ALPN: p.ALPN}
+	case "tuic":
+		ob.Protocol = "tuic"
+		ob.Credentials = Credentials{UUID: p.UUID, Password: p.Password}
+		ob.TUIC = &TUICOptions{
+			CongestionControl: p.CongestionController,
+			UDPRelayMode:      p.UDPRelayMode,
+			ZeroRTT:           p.ReduceRTT,
+		}
+		if ob.TUIC.CongestionControl == "" {
+			ob.TUIC.CongestionControl = "bbr"
+		}
+		if ob.TUIC.UDPRelayMode == "" {
+			ob.TUIC.UDPRelayMode = "native"
+		}
+		alpn := p.ALPN
+		if len(alpn) == 0 {
+			alpn = []string{"h3"}
+		}
+		ob.TLS = &TLSOptions{ServerName: p.SNI, Insecure: p.SkipCertVerify, ALPN: alpn, DisableSNI: p.DisableSNI}
+	default:
+		return nil, fmt.Errorf("unsupported clash proxy type %q", p.Type)
+	}
+	return ob, nil
+}
+
+func (p *clashProxy) tlsOptions(enabled bool, serverName string) *TLSOptions {
+	reality := p.RealityOpts.PublicKey != ""
+	if !enabled && !reality {
+		return nil
+	}
+	return &TLSOptions{
+		Reality:     reality,
+		ServerName:  serverName,
+		Insecure:    p.SkipCertVerify,
+		ALPN:        p.ALPN,
+		Fingerprint: p.ClientFingerprint,
+		PublicKey:   p.RealityOpts.PublicKey,
+		ShortID:     p.RealityOpts.ShortID,
+	}
+}
+
+// A network we can't carry over is an error rather than TCP.
+func (p *clashProxy) transportOptions() (*TransportOptions, error) {
+	switch strings.ToLower(strings.TrimSpace(p.Network)) {
+	case "", "tcp":
+		return nil, nil
+	case "ws":
+		if p.WSOpts.HTTPUpgrade {
+			return &TransportOptions{Type: "httpupgrade", Path: p.WSOpts.Path, Host: p.WSOpts.Headers["Host"]}, nil
+		}
+		return &TransportOptions{
+			Type:            "ws",
+			Path:            p.WSOpts.Path,
+			Host:            p.WSOpts.Headers["Host"],
+			MaxEarlyData:    p.WSOpts.MaxEarlyData,
+			EarlyDataHeader: p.WSOpts.EarlyDataHeaderName,
+		}, nil
+	case "grpc":
+		return &TransportOptions{Type: "grpc", ServiceName: p.GRPCOpts.ServiceName}, nil
+	case "http":
+		t := &TransportOptions{Type: "http"}
+		if len(p.HTTPOpts.Path) > 0 {
+			t.Path = p.HTTPOpts.Path[0]
+		}
+		if hosts := p.HTTPOpts.Headers["Host"]; len(hosts) > 0 {
+			t.Host = hosts[0]
+		}
+		return t, nil
+	case "h2":
+		t := &TransportOptions{Type: "http", Path: p.H2Opts.Path}
+		if len(p.H2Opts.Host) > 0 {
+			t.Host = p.H2Opts.Host[0]
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("unsupported clash network %q", p.Network)
+}
+
+func (p *clashProxy) shadowsocksPlugin() (*ShadowsocksOptions, error) {
+	opt := func(key, fallback string) string {
+		if v, ok := p.PluginOpts[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return fallback
+	}
+
+	var opts []string
+	switch p.Plugin {
+	case "obfs":
+		opts = append(opts, "obfs="+opt("mode", "http"))
+		if host := opt("host", ""); host != "" {
+			opts = append(opts, "obfs-host="+host)
+		}
+		return &ShadowsocksOptions{Plugin: "obfs-local", PluginOpts: strings.Join(opts, ";")}, nil
+	case "v2ray-plugin":
+		opts = append(opts, "mode="+opt("mode", "websocket"))
+		if tls, _ := p.PluginOpts["tls"].(bool); tls {
+			opts = append(opts, "tls")
+		}
+		for _, key := range []string{"host", "path"} {
+			if v := opt(key, ""); v != "" {
+				opts = append(opts, key+"="+v)
+			}
+		}
+		return &ShadowsocksOptions{Plugin: "v2ray-plugin", PluginOpts: strings.Join(opts, ";")}, nil
+	}
+	return nil, fmt.Errorf("unsupported clash plugin %q", p.Plugin)
+}
+
+func clashBandwidth(v string) int {
+	v = strings.TrimSpace(v)
+	end := strings.IndexFunc(v, func(r rune) bool { return r < '0' || r > '9' })
+	if end != -1 {
+		v = v[:end]
+	}
+	n, _ := strconv.Atoi(v)
+	return n
+}
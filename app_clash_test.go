@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const clashConfig = `
+proxies:
+  - name: "ss chacha"
+    type: ss
+    server: 198.51.100.1
+    port: 8388
+    cipher: chacha20-poly1305
+    password: pass
+  - name: "ss aead"
+    type: ss
+    server: 198.51.100.2
+    port: 8388
+    cipher: AEAD_AES_256_GCM
+    password: pass
+  - name: "vless grpc"
+    type: vless
+    server: grpc.example.com
+    port: 443
+    uuid: d342d11e-d424-4583-b36e-524ab1f0afa4
+    tls: true
+    servername: grpc.example.com
+    network: GRPC
+    grpc-opts:
+      grpc-service-name: svc
+  - name: "vless kcp"
+    type: vless
+    server: kcp.example.com
+    port: 443
+    uuid: d342d11e-d424-4583-b36e-524ab1f0afa4
+    network: kcp
+  - name: "trojan xhttp"
+    type: trojan
+    server: x.example.com
+    port: 443
+    password: pass
+    network: xhttp
+  - name: "snell"
+    type: snell
+    server: snell.example.com
+    port: 443
+`
+
+func TestClashLinks(t *testing.T) {
+	links, skipped := clashLinks(clashConfig)
+
+	parsed := map[string]*Outbound{}
+	for _, link := range links {
+		ob, name, err := parseProfileKey(link)
+		if err != nil {
+			t.Fatalf("%s: %v", link, err)
+		}
+		parsed[name] = ob
+	}
+
+	for name, method := range map[string]string{
+		"ss chacha": "chacha20-ietf-poly1305",
+		"ss aead":   "aes-256-gcm",
+	} {
+		if ob := parsed[name]; ob == nil || ob.Credentials.Method != method {
+			t.Errorf("%s: want method %s, got %+v", name, method, ob)
+		}
+	}
+	if ob := parsed["vless grpc"]; ob == nil || ob.Transport == nil || ob.Transport.Type != "grpc" || ob.Transport.ServiceName != "svc" {
+		t.Errorf("vless grpc: want a grpc transport, got %+v", ob)
+	}
+
+	want := []string{"vless kcp", "trojan xhttp", "snell"}
+	if len(skipped) != len(want) {
+		t.Fatalf("skipped %v, want %v", skipped, want)
+	}
+	for i, name := range want {
+		if !strings.HasPrefix(skipped[i], name+": ") {
+			t.Errorf("skipped[%d] = %q, want %s with a reason", i, skipped[i], name)
+		}
+	}
+}
+
+func TestClashLinksBadYAML(t *testing.T) {
+	links, skipped := clashLinks("proxies:\n  - name: [unclosed\n")
+	if len(links) != 0 || len(skipped) != 1 {
+		t.Errorf("links %v, skipped %v", links, skipped)
+	}
+}
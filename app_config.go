@@ -1,282 +1,619 @@
 package main
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net"
-	"net/url"
 	"runtime"
-	"strconv"
 )
 
-func (a *App) generateConfig(vlessLink string) (string, error) {
-	u, err := url.Parse(vlessLink)
+func (a *App) generateConfig(ob *Outbound) (string, error) {
+	config, err := a.builder().build(ob)
 	if err != nil {
-		return "", fmt.Errorf("bad link")
+		return "", err
+	}
+	return a.marshalConfig(config)
+}
+
+func (a *App) marshalConfig(config *sbConfig) (string, error) {
+	bytes, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if bytes, err = applyConfigOverlay(bytes, a.settingsSnapshot().ConfigOverlay); err != nil {
+		return "", fmt.Errorf("config overlay: %w", err)
 	}
+	return string(bytes), nil
+}
+
+// goos is a field so the config can be generated for any platform.
+type configBuilder struct {
+	settings Settings
+	goos     string
+	config   sbConfig
 
-	q := u.Query()
-	uuid := u.User.Username()
-	host := u.Hostname()
-	port, _ := strconv.Atoi(u.Port())
+	// servers are routed direct so the tunnel does not loop into itself.
+	servers []string
+	skipped []string
+	failed  map[string]error
 
-	transportType := q.Get("type")
-	if transportType == "" {
-		transportType = "tcp"
+	ruleSetFiles map[string]string
+}
+
+// Upstream members only serve as a detour and stay out of the group.
+type groupMember struct {
+	Tag      string
+	Name     string
+	Outbound *Outbound
+	Detour   string
+	Upstream bool
+}
+
+func newConfigBuilder(settings Settings) *configBuilder {
+	return &configBuilder{
+		settings: settings,
+		goos:     runtime.GOOS,
+		config: sbConfig{
+			Inbounds:  []sbInbound{},
+			Outbounds: []sbOutbound{},
+			Route:     &sbRoute{Rules: []sbRule{}},
+		},
 	}
-	security := q.Get("security")
+}
 
-	sni := q.Get("sni")
-	pbk := q.Get("pbk")
-	sid := q.Get("sid")
-	fp := q.Get("fp")
-	if fp == "" {
-		fp = "chrome"
+func (b *configBuilder) build(ob *Outbound) (*sbConfig, error) {
+	if err := b.addProxy("proxy", ob); err != nil {
+		return nil, err
+	}
+	return b.finish()
+}
+
+func (b *configBuilder) buildURLTest(members []groupMember, group FailoverGroup) (*sbConfig, error) {
+	tags := b.addMembers(members)
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no usable profiles in the failover group")
 	}
 
-	path := q.Get("path")
-	hostHeader := q.Get("host")
-	serviceName := q.Get("serviceName")
-	flow := q.Get("flow")
+	group = group.withDefaults()
+	b.config.Outbounds = append([]sbOutbound{{
+		Type:      "urltest",
+		Tag:       "proxy",
+		Outbounds: tags,
+		URL:       group.URL,
+		Interval:  group.Interval,
+		Tolerance: group.Tolerance,
+	}}, b.config.Outbounds...)
+	return b.finish()
+}
 
-	vlessOutbound := map[string]interface{}{
-		"type":            "vless",
-		"tag":             "proxy",
-		"server":          host,
-		"server_port":     port,
-		"uuid":            uuid,
-		"flow":            flow,
-		"packet_encoding": "xudp",
+const selectorTag = "proxy"
+
+// Unlike other members, a selected member that fails to build is an error.
+func (b *configBuilder) buildSelector(members []groupMember, selected string) (*sbConfig, error) {
+	tags := b.addMembers(members)
+	if err, ok := b.failed[selected]; ok {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no profiles to select from")
 	}
 
-	tlsConfig := map[string]interface{}{
-		"enabled":     false,
-		"server_name": sni,
-		"utls": map[string]interface{}{
-			"enabled":     true,
-			"fingerprint": fp,
-		},
+	b.config.Outbounds = append([]sbOutbound{{
+		Type:      "selector",
+		Tag:       selectorTag,
+		Outbounds: tags,
+		Default:   selected,
+	}}, b.config.Outbounds...)
+	return b.finish()
+}
+
+func (b *configBuilder) addMembers(members []groupMember) []string {
+	if b.failed == nil {
+		b.failed = map[string]error{}
 	}
+	built := map[string]bool{}
+	pending := members
+	for len(pending) > 0 {
+		waiting := []groupMember{}
+		for _, m := range pending {
+			var err error
+			switch {
+			case m.Detour == "" || built[m.Detour]:
+				err = b.addChainedProxy(m.Tag, m.Outbound, m.Detour)
+			case b.failed[m.Detour] != nil:
+				err = fmt.Errorf("upstream: %w", b.failed[m.Detour])
+			default:
+				waiting = append(waiting, m)
+				continue
+			}
+			if err != nil {
+				b.failed[m.Tag] = err
+				b.skipped = append(b.skipped, m.Name+": "+err.Error())
+			} else {
+				built[m.Tag] = true
+			}
+		}
 
-	if security == "tls" {
-		tlsConfig["enabled"] = true
-		vlessOutbound["tls"] = tlsConfig
-	} else if security == "reality" {
-		tlsConfig["enabled"] = true
-		tlsConfig["reality"] = map[string]interface{}{
-			"enabled":    true,
-			"public_key": pbk,
-			"short_id":   sid,
+		// The rest detour in a cycle or through a member outside the config.
+		if len(waiting) == len(pending) {
+			for _, m := range waiting {
+				b.failed[m.Tag] = fmt.Errorf("upstream %s is missing or detours in a cycle", m.Detour)
+				b.skipped = append(b.skipped, m.Name+": "+b.failed[m.Tag].Error())
+			}
+			break
 		}
-		vlessOutbound["tls"] = tlsConfig
+		pending = waiting
 	}
 
-	transportConfig := map[string]interface{}{
-		"type": transportType,
+	tags := []string{}
+	for _, m := range members {
+		if built[m.Tag] && !m.Upstream {
+			tags = append(tags, m.Tag)
+		}
 	}
+	return tags
+}
+
+func (b *configBuilder) finish() (*sbConfig, error) {
+	b.addOutbound(sbOutbound{Type: "direct", Tag: "direct"})
+
+	if err := b.addInbounds(); err != nil {
+		return nil, err
+	}
+	if err := b.addRoute(); err != nil {
+		return nil, err
+	}
+	if err := b.addDNS(); err != nil {
+		return nil, fmt.Errorf("dns: %w", err)
+	}
+
+	b.config.Log = &sbLog{Level: "info", Timestamp: true}
+	b.config.Experimental = &sbExperimental{
+		ClashAPI:  &sbClashAPI{ExternalController: "127.0.0.1:9090"},
+		CacheFile: &sbCacheFile{Enabled: true, StoreFakeIP: b.useFakeIP(), StoreRDRC: true},
+	}
+	return &b.config, nil
+}
 
-	if transportType == "ws" {
-		transportConfig["path"] = path
-		if hostHeader != "" {
-			transportConfig["headers"] = map[string]string{"Host": hostHeader}
+func (b *configBuilder) addOutbound(o sbOutbound) {
+	b.config.Outbounds = append(b.config.Outbounds, o)
+}
+
+func (b *configBuilder) addRule(r sbRule) {
+	b.config.Route.Rules = append(b.config.Route.Rules, r)
+}
+
+func (b *configBuilder) addDirectRule(r sbRule) {
+	r.Action = "route"
+	r.Outbound = "direct"
+	b.addRule(r)
+}
+
+func (b *configBuilder) addProxy(tag string, ob *Outbound) error {
+	return b.addChainedProxy(tag, ob, "")
+}
+
+func (b *configBuilder) addChainedProxy(tag string, ob *Outbound, detour string) error {
+	if ob.Raw == nil && ob.Protocol == "wireguard" {
+		endpoint := buildWireGuardEndpoint(ob.WireGuard)
+		endpoint.Tag = tag
+		endpoint.Detour = detour
+		b.config.Endpoints = append(b.config.Endpoints, endpoint)
+	} else if ob.Raw != nil {
+		outbounds, err := buildRawOutbounds(ob, tag, detour)
+		if err != nil {
+			return err
 		}
-	} else if transportType == "grpc" {
-		transportConfig["service_name"] = serviceName
-		if serviceName == "" {
-			transportConfig["service_name"] = path
+		for _, o := range outbounds {
+			b.addOutbound(o)
+		}
+	} else {
+		proxy, err := buildProxyOutbound(ob)
+		if err != nil {
+			return err
 		}
-	} else if transportType == "http" {
-		transportConfig["host"] = []string{hostHeader}
-		transportConfig["path"] = path
+		proxy.setTag(tag)
+		proxy.setDetour(detour)
+		b.addOutbound(*proxy)
 	}
 
-	if transportType != "tcp" {
-		vlessOutbound["transport"] = transportConfig
+	if detour == "" {
+		b.addServer(ob.Server)
+		if ob.Raw == nil && ob.WireGuard != nil {
+			for _, peer := range ob.WireGuard.Peers {
+				b.addServer(peer.Server)
+			}
+		}
 	}
+	return nil
+}
 
-	outbounds := []map[string]interface{}{
-		vlessOutbound,
-		{"type": "direct", "tag": "direct"},
+func (b *configBuilder) addServer(host string) {
+	if host == "" {
+		return
 	}
+	for _, s := range b.servers {
+		if s == host {
+			return
+		}
+	}
+	b.servers = append(b.servers, host)
+}
 
-	inbounds := []map[string]interface{}{}
+func (b *configBuilder) ipv6Mode() string {
+	switch b.settings.IPv6 {
+	case "on", "block":
+		return b.settings.IPv6
+	}
+	return "off"
+}
 
-	inbounds = append(inbounds, map[string]interface{}{
-		"type":        "mixed",
-		"tag":         "mixed-in",
-		"listen":      "127.0.0.1",
-		"listen_port": a.Settings.MixedPort,
-		"sniff":       true,
+func (b *configBuilder) addInbounds() error {
+	b.config.Inbounds = append(b.config.Inbounds, sbInbound{
+		Type:       "mixed",
+		Tag:        "mixed-in",
+		Listen:     "127.0.0.1",
+		ListenPort: b.settings.MixedPort,
+		Sniff:      true,
 	})
 
-	if a.Settings.RunMode == "tun" {
-		tunConfig := map[string]interface{}{
-			"type":                       "tun",
-			"tag":                        "tun-in",
-			"address":                    []string{"172.19.0.1/30"},
-			"mtu":                        9000,
-			"auto_route":                 true,
-			"strict_route":               true,
-			"stack":                      "system",
-			"sniff":                      true,
-			"sniff_override_destination": true,
-		}
+	if b.settings.RunMode != "tun" {
+		return nil
+	}
+	tun, err := b.buildTunInbound()
+	if err != nil {
+		return err
+	}
+	b.config.Inbounds = append(b.config.Inbounds, tun)
+	return nil
+}
 
-		if runtime.GOOS == "linux" {
-			tunConfig["interface_name"] = "tun0"
-		}
+func (b *configBuilder) addRoute() error {
+	s := b.settings
+	route := b.config.Route
+	route.AutoDetectInterface = true
+	route.Final = "proxy"
+	route.DefaultDomainResolver = "local_dns"
 
-		inbounds = append(inbounds, tunConfig)
+	regions, err := b.smartRegions()
+	if err != nil {
+		return err
 	}
-
-	ruleSets := []map[string]interface{}{}
-	if a.Settings.RoutingMode == "smart" {
-		ruleSets = append(ruleSets, map[string]interface{}{
-			"tag":             "geoip-ru",
-			"type":            "remote",
-			"format":          "binary",
-			"url":             "https://raw.githubusercontent.com/SagerNet/sing-geoip/rule-set/geoip-ru.srs",
-			"download_detour": "proxy",
+	for _, r := range regions {
+		route.RuleSet = append(route.RuleSet, sbRuleSet{
+			Tag:            r.ruleSetTag(),
+			Type:           "remote",
+			Format:         "binary",
+			URL:            r.ruleSetURL(),
+			DownloadDetour: "proxy",
 		})
 	}
 
-	rules := []map[string]interface{}{}
+	b.addRule(sbRule{Protocol: []string{"dns"}, Action: "hijack-dns"})
 
-	rules = append(rules, map[string]interface{}{
-		"protocol": "dns",
-		"action":   "hijack-dns",
-	})
+	if s.RunMode == "tun" {
+		b.addRule(sbRule{Inbound: []string{"tun-in"}, Action: "sniff"})
+	}
 
-	if a.Settings.RunMode == "tun" {
-		rules = append(rules, map[string]interface{}{
-			"inbound": "tun-in",
-			"action":  "sniff",
-		})
+	if b.ipv6Mode() == "block" {
+		b.addRule(sbRule{IPVersion: 6, Action: "reject"})
 	}
 
-	for _, ur := range a.Settings.UserRules {
-		r := map[string]interface{}{}
-		if ur.Outbound == "block" {
-			r["action"] = "reject"
+	if err := b.addUserRules(); err != nil {
+		return fmt.Errorf("user rules: %w", err)
+	}
+
+	b.addDirectRule(sbRule{IPIsPrivate: true})
+
+	if s.RoutingMode == "smart" {
+		var domains, geoip []string
+		for _, r := range regions {
+			domains = append(domains, r.Domains...)
+			geoip = append(geoip, r.ruleSetTag())
+		}
+		domains = append(domains, s.DirectDomains...)
+		if len(domains) > 0 {
+			b.addDirectRule(sbRule{DomainSuffix: domains})
+		}
+		if len(geoip) > 0 {
+			b.addDirectRule(sbRule{RuleSet: geoip})
+		}
+	}
+
+	var serverIPs, serverDomains []string
+	for _, host := range b.servers {
+		if ip := net.ParseIP(host); ip == nil {
+			serverDomains = append(serverDomains, host)
+		} else if ip.To4() != nil {
+			serverIPs = append(serverIPs, host+"/32")
 		} else {
-			r["action"] = "route"
-			r["outbound"] = ur.Outbound
+			serverIPs = append(serverIPs, host+"/128")
 		}
+	}
+	if len(serverIPs) > 0 {
+		b.addDirectRule(sbRule{IPCIDR: serverIPs})
+	}
+	if len(serverDomains) > 0 {
+		b.addDirectRule(sbRule{Domain: serverDomains})
+	}
+
+	b.addDirectRule(sbRule{IPCIDR: []string{"8.8.8.8/32", "1.1.1.1/32"}})
+	b.addDirectRule(sbRule{Inbound: []string{"clash-api"}})
 
-		if ur.Type == "domain" {
-			r["domain_suffix"] = []string{ur.Value}
-		} else if ur.Type == "ip" {
-			r["ip_cidr"] = []string{ur.Value}
-		} else if ur.Type == "process" {
-			r["process_name"] = []string{ur.Value}
+	// Cached copies keep routing working while the proxy is down.
+	for i, rs := range route.RuleSet {
+		if path, ok := b.ruleSetFiles[rs.URL]; ok && rs.Type == "remote" {
+			route.RuleSet[i] = sbRuleSet{Tag: rs.Tag, Type: "local", Format: rs.Format, Path: path}
 		}
-		rules = append(rules, r)
 	}
+	return nil
+}
 
-	rules = append(rules, map[string]interface{}{
-		"ip_is_private": true,
-		"action":        "route",
-		"outbound":      "direct",
-	})
+func buildProxyOutbound(ob *Outbound) (*sbOutbound, error) {
+	// Profiles saved before xhttp links were rejected on import.
+	if ob.Transport != nil && ob.Transport.Type == "xhttp" {
+		return nil, fmt.Errorf("xhttp transport is not supported by sing-box")
+	}
 
-	if a.Settings.RoutingMode == "smart" {
-		if len(a.Settings.RuDomains) > 0 {
-			rules = append(rules, map[string]interface{}{
-				"domain_suffix": a.Settings.RuDomains,
-				"action":        "route",
-				"outbound":      "direct",
-			})
+	switch ob.Protocol {
+	case "vless":
+		return buildVlessOutbound(ob), nil
+	case "trojan":
+		return buildTrojanOutbound(ob), nil
+	case "vmess":
+		return buildVmessOutbound(ob), nil
+	case "shadowsocks":
+		return buildShadowsocksOutbound(ob), nil
+	case "hysteria2":
+		return buildHysteria2Outbound(ob), nil
+	case "tuic":
+		return buildTuicOutbound(ob), nil
+	}
+	return nil, fmt.Errorf("unsupported protocol: %s", ob.Protocol)
+}
+
+func withStreamSettings(outbound *sbOutbound, ob *Outbound) *sbOutbound {
+	outbound.TLS = buildTLSConfig(ob.TLS)
+	outbound.Transport = buildTransportConfig(ob.Transport)
+	outbound.Multiplex = buildMultiplexConfig(ob.Multiplex)
+	return outbound
+}
+
+func buildMultiplexConfig(mux *MultiplexOptions) *sbMultiplex {
+	if mux == nil || !mux.Enabled {
+		return nil
+	}
+
+	muxConfig := &sbMultiplex{
+		Enabled:        true,
+		Protocol:       mux.Protocol,
+		MaxConnections: mux.MaxConnections,
+		MinStreams:     mux.MinStreams,
+		MaxStreams:     mux.MaxStreams,
+		Padding:        mux.Padding,
+	}
+	if mux.BrutalUpMbps > 0 && mux.BrutalDownMbps > 0 {
+		muxConfig.Brutal = &sbBrutal{
+			Enabled:  true,
+			UpMbps:   mux.BrutalUpMbps,
+			DownMbps: mux.BrutalDownMbps,
 		}
+	}
+	return muxConfig
+}
 
-		rules = append(rules, map[string]interface{}{
-			"rule_set": "geoip-ru",
-			"action":   "route",
-			"outbound": "direct",
-		})
+func buildVlessOutbound(ob *Outbound) *sbOutbound {
+	return withStreamSettings(&sbOutbound{
+		Type:           "vless",
+		Tag:            "proxy",
+		Server:         ob.Server,
+		ServerPort:     ob.Port,
+		UUID:           ob.Credentials.UUID,
+		Flow:           ob.Credentials.Flow,
+		PacketEncoding: "xudp",
+	}, ob)
+}
+
+func buildTrojanOutbound(ob *Outbound) *sbOutbound {
+	return withStreamSettings(&sbOutbound{
+		Type:       "trojan",
+		Tag:        "proxy",
+		Server:     ob.Server,
+		ServerPort: ob.Port,
+		Password:   ob.Credentials.Password,
+	}, ob)
+}
+
+func buildVmessOutbound(ob *Outbound) *sbOutbound {
+	return withStreamSettings(&sbOutbound{
+		Type:       "vmess",
+		Tag:        "proxy",
+		Server:     ob.Server,
+		ServerPort: ob.Port,
+		UUID:       ob.Credentials.UUID,
+		Security:   ob.Credentials.Method,
+		AlterID:    ob.Credentials.AlterID,
+	}, ob)
+}
+
+func buildShadowsocksOutbound(ob *Outbound) *sbOutbound {
+	outbound := &sbOutbound{
+		Type:       "shadowsocks",
+		Tag:        "proxy",
+		Server:     ob.Server,
+		ServerPort: ob.Port,
+		Method:     ob.Credentials.Method,
+		Password:   ob.Credentials.Password,
+		Multiplex:  buildMultiplexConfig(ob.Multiplex),
+	}
+	if ss := ob.Shadowsocks; ss != nil && ss.Plugin != "" {
+		outbound.Plugin = ss.Plugin
+		outbound.PluginOpts = ss.PluginOpts
 	}
+	return outbound
+}
 
-	if ip := net.ParseIP(host); ip != nil {
-		rules = append(rules, map[string]interface{}{
-			"ip_cidr":  []string{host + "/32"},
-			"action":   "route",
-			"outbound": "direct",
-		})
-	} else {
-		rules = append(rules, map[string]interface{}{
-			"domain":   []string{host},
-			"action":   "route",
-			"outbound": "direct",
-		})
+func buildHysteria2Outbound(ob *Outbound) *sbOutbound {
+	hy := ob.Hysteria2
+	outbound := &sbOutbound{
+		Type:        "hysteria2",
+		Tag:         "proxy",
+		Server:      ob.Server,
+		ServerPort:  ob.Port,
+		ServerPorts: hy.Ports,
+		Password:    ob.Credentials.Password,
+		UpMbps:      hy.UpMbps,
+		DownMbps:    hy.DownMbps,
+		TLS:         buildQUICTLSConfig(ob.TLS),
 	}
+	if hy.ObfsType != "" {
+		outbound.Obfs = &sbObfs{Type: hy.ObfsType, Password: hy.ObfsPassword}
+	}
+	return outbound
+}
 
-	rules = append(rules, map[string]interface{}{
-		"ip_cidr":  []string{"8.8.8.8/32", "1.1.1.1/32"},
-		"action":   "route",
-		"outbound": "direct",
-	})
+func buildTuicOutbound(ob *Outbound) *sbOutbound {
+	return &sbOutbound{
+		Type:              "tuic",
+		Tag:               "proxy",
+		Server:            ob.Server,
+		ServerPort:        ob.Port,
+		UUID:              ob.Credentials.UUID,
+		Password:          ob.Credentials.Password,
+		CongestionControl: ob.TUIC.CongestionControl,
+		UDPRelayMode:      ob.TUIC.UDPRelayMode,
+		ZeroRTTHandshake:  ob.TUIC.ZeroRTT,
+		TLS:               buildQUICTLSConfig(ob.TLS),
+	}
+}
 
-	rules = append(rules, map[string]interface{}{
-		"inbound":  "clash-api",
-		"action":   "route",
-		"outbound": "direct",
-	})
+// QUIC outbounds cannot use uTLS fingerprints.
+func buildQUICTLSConfig(t *TLSOptions) *sbTLS {
+	if t == nil {
+		t = &TLSOptions{}
+	}
+	return &sbTLS{
+		Enabled:    true,
+		ServerName: t.ServerName,
+		Insecure:   t.Insecure,
+		ALPN:       t.ALPN,
+		DisableSNI: t.DisableSNI,
+	}
+}
 
-	dnsRules := []map[string]interface{}{}
-	
-	if a.Settings.RoutingMode == "smart" {
-		dnsRules = append(dnsRules, map[string]interface{}{
-			"domain_suffix": a.Settings.RuDomains,
-			"server":        "local_dns",
+func buildWireGuardEndpoint(wg *WireGuardOptions) sbEndpoint {
+	mtu := wg.MTU
+	if mtu == 0 {
+		mtu = 1408
+	}
+
+	peers := []sbWireGuardPeer{}
+	for _, p := range wg.Peers {
+		peers = append(peers, sbWireGuardPeer{
+			Address:                     p.Server,
+			Port:                        p.Port,
+			PublicKey:                   p.PublicKey,
+			PreSharedKey:                p.PresharedKey,
+			AllowedIPs:                  p.AllowedIPs,
+			PersistentKeepaliveInterval: p.Keepalive,
 		})
 	}
 
-	dnsConfig := map[string]interface{}{
-		"servers": []map[string]interface{}{
-			{
-				"tag":    "remote_dns",
-				"type":   "udp",
-				"server": "8.8.8.8",
-				"detour": "proxy",
-			},
-			{
-				"tag":  "local_dns",
-				"type": "local",
-			},
-		},
-		"rules":    dnsRules,
-		"final":    "remote_dns",
-		"strategy": "ipv4_only",
+	return sbEndpoint{
+		Type:       "wireguard",
+		Tag:        "proxy",
+		MTU:        mtu,
+		Address:    wg.Addresses,
+		PrivateKey: wg.PrivateKey,
+		Peers:      peers,
 	}
+}
 
-	fullConfig := map[string]interface{}{
-		"log": map[string]interface{}{
-			"level":     "info",
-			"timestamp": true,
-		},
-		"experimental": map[string]interface{}{
-			"clash_api": map[string]interface{}{
-				"external_controller": "127.0.0.1:9090",
-			},
-			"cache_file": map[string]interface{}{
-				"enabled":    true,
-				"store_rdrc": true,
-			},
-		},
-		"dns":       dnsConfig,
-		"inbounds":  inbounds,
-		"outbounds": outbounds,
-		"route": map[string]interface{}{
-			"rule_set":                ruleSets,
-			"rules":                   rules,
-			"auto_detect_interface":   true,
-			"final":                   "proxy",
-			"default_domain_resolver": "local_dns",
-		},
+func buildTLSConfig(t *TLSOptions) *sbTLS {
+	if t == nil {
+		return nil
 	}
 
-	bytes, err := json.MarshalIndent(fullConfig, "", "  ")
-	if err != nil {
-		return "", err
+	fp := t.Fingerprint
+	if fp == "" {
+		fp = "chrome"
 	}
-	return string(bytes), nil
+
+	tlsConfig := &sbTLS{
+		Enabled:    true,
+		ServerName: t.ServerName,
+		ALPN:       t.ALPN,
+		Insecure:   t.Insecure,
+	}
+
+	if ech := buildECHConfig(t); ech != nil {
+		tlsConfig.ECH = ech
+	} else {
+		tlsConfig.UTLS = &sbUTLS{Enabled: true, Fingerprint: fp}
+	}
+
+	if f := t.Fragment; f != nil {
+		tlsConfig.Fragment = f.Fragment
+		tlsConfig.RecordFragment = f.RecordFragment
+		if f.Fragment {
+			tlsConfig.FragmentFallbackDelay = f.FallbackDelay
+		}
+	}
+
+	if t.Reality {
+		tlsConfig.Reality = &sbReality{
+			Enabled:   true,
+			PublicKey: t.PublicKey,
+			ShortID:   t.ShortID,
+		}
+	}
+	return tlsConfig
+}
+
+// Without a config list sing-box looks ECH up in the HTTPS record.
+func buildECHConfig(t *TLSOptions) *sbECH {
+	if !t.ECH {
+		return nil
+	}
+	ech := &sbECH{Enabled: true, QueryServerName: t.ECHQueryServerName}
+	if t.ECHConfig != "" {
+		if raw, err := decodeBase64Loose(t.ECHConfig); err == nil {
+			ech.Config = []string{
+				"-----BEGIN ECH CONFIGS-----",
+				base64.StdEncoding.EncodeToString(raw),
+				"-----END ECH CONFIGS-----",
+			}
+		}
+	}
+	return ech
+}
+
+func buildTransportConfig(t *TransportOptions) *sbTransport {
+	if t == nil {
+		return nil
+	}
+
+	transportConfig := &sbTransport{Type: t.Type}
+
+	switch t.Type {
+	case "ws":
+		transportConfig.Path = t.Path
+		if t.Host != "" {
+			transportConfig.Headers = map[string]string{"Host": t.Host}
+		}
+		if t.MaxEarlyData > 0 {
+			transportConfig.MaxEarlyData = t.MaxEarlyData
+			transportConfig.EarlyDataHeaderName = t.EarlyDataHeader
+		}
+	case "grpc":
+		transportConfig.ServiceName = t.ServiceName
+		if t.ServiceName == "" {
+			transportConfig.ServiceName = t.Path
+		}
+	case "http", "httpupgrade":
+		transportConfig.Host = t.Host
+		transportConfig.Path = t.Path
+	}
+	return transportConfig
 }
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+type DNSSettings struct {
+	Servers []DNSServer `json:"servers"`
+	Rules   []DNSRule   `json:"rules,omitempty"`
+	// Empty Final means the first server; empty Strategy follows the IPv6 setting.
+	Final    string `json:"final,omitempty"`
+	Strategy string `json:"strategy,omitempty"`
+
+	DisableCache     bool `json:"disable_cache,omitempty"`
+	DisableExpire    bool `json:"disable_expire,omitempty"`
+	IndependentCache bool `json:"independent_cache,omitempty"`
+	CacheCapacity    int  `json:"cache_capacity,omitempty"`
+
+	FakeIP FakeIPSettings `json:"fake_ip"`
+}
+
+type FakeIPSettings struct {
+	Enabled    bool   `json:"enabled"`
+	Inet4Range string `json:"inet4_range,omitempty"`
+	Inet6Range string `json:"inet6_range,omitempty"`
+}
+
+// Address is host[:port], with an optional /path for https.
+type DNSServer struct {
+	Tag     string `json:"tag"`
+	Type    string `json:"type"`
+	Address string `json:"address"`
+	Detour  string `json:"detour,omitempty"`
+}
+
+type DNSRule struct {
+	Domains []string `json:"domains"`
+	Server  string   `json:"server"`
+}
+
+var dnsDefaultPorts = map[string]int{
+	"udp":   53,
+	"tcp":   53,
+	"tls":   853,
+	"https": 443,
+	"quic":  853,
+}
+
+var dnsStrategies = map[string]bool{
+	"prefer_ipv4": true,
+	"prefer_ipv6": true,
+	"ipv4_only":   true,
+	"ipv6_only":   true,
+}
+
+func defaultDNSSettings() DNSSettings {
+	return DNSSettings{
+		Servers: []DNSServer{{Tag: "remote_dns", Type: "udp", Address: "8.8.8.8", Detour: "proxy"}},
+		Final:   "remote_dns",
+	}
+}
+
+func (d DNSSettings) withDefaults() DNSSettings {
+	if len(d.Servers) == 0 {
+		d.Servers = defaultDNSSettings().Servers
+	}
+	if d.Final == "" {
+		d.Final = d.Servers[0].Tag
+	}
+	if d.FakeIP.Inet4Range == "" {
+		d.FakeIP.Inet4Range = "198.18.0.0/15"
+	}
+	if d.FakeIP.Inet6Range == "" {
+		d.FakeIP.Inet6Range = "fc00::/18"
+	}
+	return d
+}
+
+func (d DNSSettings) validate() error {
+	tags := map[string]bool{"local_dns": true, "fakeip": true}
+	for i, s := range d.Servers {
+		if s.Tag == "" {
+			return fmt.Errorf("dns server %d has no tag", i+1)
+		}
+		if tags[s.Tag] || isRegionTag(s.Tag) {
+			return fmt.Errorf("dns server tag %q is used twice or reserved", s.Tag)
+		}
+		tags[s.Tag] = true
+		if _, err := s.build(); err != nil {
+			return fmt.Errorf("dns server %s: %w", s.Tag, err)
+		}
+	}
+	if d.Final != "" && !tags[d.Final] {
+		return fmt.Errorf("final dns server %q not found", d.Final)
+	}
+	for i, r := range d.Rules {
+		if len(r.Domains) == 0 {
+			return fmt.Errorf("dns rule %d has no domains", i+1)
+		}
+		if !tags[r.Server] {
+			return fmt.Errorf("dns rule %d: server %q not found", i+1, r.Server)
+		}
+	}
+	if d.Strategy != "" && !dnsStrategies[d.Strategy] {
+		return fmt.Errorf("unknown dns strategy %q", d.Strategy)
+	}
+	if d.CacheCapacity < 0 {
+		return fmt.Errorf("dns cache capacity must not be negative")
+	}
+	if d.FakeIP.Enabled {
+		if err := checkFakeIPRange(d.FakeIP.Inet4Range, false); err != nil {
+			return fmt.Errorf("fakeip inet4 range: %w", err)
+		}
+		if err := checkFakeIPRange(d.FakeIP.Inet6Range, true); err != nil {
+			return fmt.Errorf("fakeip inet6 range: %w", err)
+		}
+	}
+	return nil
+}
+
+func checkFakeIPRange(cidr string, v6 bool) error {
+	ip, _, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return err
+	}
+	if (ip.To4() == nil) != v6 {
+		return fmt.Errorf("%s is the wrong address family", cidr)
+	}
+	return nil
+}
+
+func (s DNSServer) build() (sbDNSServer, error) {
+	port, ok := dnsDefaultPorts[s.Type]
+	if !ok {
+		return sbDNSServer{}, fmt.Errorf("unknown type %q", s.Type)
+	}
+
+	address := strings.TrimSpace(s.Address)
+	if i := strings.Index(address, "://"); i >= 0 {
+		address = address[i+3:]
+	}
+	path := ""
+	if i := strings.Index(address, "/"); i >= 0 {
+		address, path = address[:i], address[i:]
+	}
+	if path != "" && s.Type != "https" {
+		return sbDNSServer{}, fmt.Errorf("only https servers take a path")
+	}
+
+	host := address
+	if h, p, err := net.SplitHostPort(address); err == nil {
+		n, err := strconv.Atoi(p)
+		if err != nil || n <= 0 || n > 65535 {
+			return sbDNSServer{}, fmt.Errorf("invalid port %q", p)
+		}
+		host, port = h, n
+	}
+	host = strings.Trim(host, "[]")
+	if host == "" {
+		return sbDNSServer{}, fmt.Errorf("no address")
+	}
+
+	server := sbDNSServer{
+		Tag:    s.Tag,
+		Type:   s.Type,
+		Server: host,
+		Path:   path,
+	}
+	if port != dnsDefaultPorts[s.Type] {
+		server.ServerPort = port
+	}
+	if s.Detour == "proxy" {
+		server.Detour = "proxy"
+	}
+	if net.ParseIP(host) == nil {
+		server.DomainResolver = "local_dns"
+	}
+	return server, nil
+}
+
+// FakeIP only makes sense when the TUN inbound gets the connections.
+func (b *configBuilder) useFakeIP() bool {
+	return b.settings.DNS.FakeIP.Enabled && b.settings.RunMode == "tun"
+}
+
+func (b *configBuilder) addDNS() error {
+	settings := b.settings.DNS.withDefaults()
+	if err := settings.validate(); err != nil {
+		return err
+	}
+
+	strategy := settings.Strategy
+	if strategy == "" {
+		strategy = "ipv4_only"
+		if b.ipv6Mode() == "on" {
+			strategy = "prefer_ipv4"
+		}
+	}
+
+	dns := &sbDNS{
+		Final:            settings.Final,
+		Strategy:         strategy,
+		DisableCache:     settings.DisableCache,
+		DisableExpire:    settings.DisableExpire,
+		IndependentCache: settings.IndependentCache,
+		CacheCapacity:    settings.CacheCapacity,
+	}
+	for _, s := range settings.Servers {
+		server, _ := s.build()
+		dns.Servers = append(dns.Servers, server)
+	}
+	dns.Servers = append(dns.Servers, sbDNSServer{Tag: "local_dns", Type: "local"})
+
+	for _, r := range settings.Rules {
+		dns.Rules = append(dns.Rules, sbDNSRule{DomainSuffix: r.Domains, Server: r.Server})
+	}
+	regions, err := b.smartRegions()
+	if err != nil {
+		return err
+	}
+	var localDomains []string
+	for _, r := range regions {
+		if !b.settings.RegionDNS || r.DNS == "" {
+			localDomains = append(localDomains, r.Domains...)
+			continue
+		}
+		dns.Servers = append(dns.Servers, sbDNSServer{Tag: r.dnsTag(), Type: "udp", Server: r.DNS})
+		dns.Rules = append(dns.Rules, sbDNSRule{DomainSuffix: r.Domains, Server: r.dnsTag()})
+	}
+	if b.settings.RoutingMode == "smart" {
+		localDomains = append(localDomains, b.settings.DirectDomains...)
+	}
+	if len(localDomains) > 0 {
+		dns.Rules = append(dns.Rules, sbDNSRule{DomainSuffix: localDomains, Server: "local_dns"})
+	}
+
+	if b.useFakeIP() {
+		dns.Servers = append(dns.Servers, sbDNSServer{
+			Tag:        "fakeip",
+			Type:       "fakeip",
+			Inet4Range: settings.FakeIP.Inet4Range,
+			Inet6Range: settings.FakeIP.Inet6Range,
+		})
+		dns.Rules = append(dns.Rules, sbDNSRule{QueryType: []string{"A", "AAAA"}, Server: "fakeip"})
+	}
+	b.config.DNS = dns
+	return nil
+}
+
+func (a *App) ValidateDNS(dns DNSSettings) string {
+	if err := dns.withDefaults().validate(); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "OK"
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDNSServerBuild(t *testing.T) {
+	cases := map[string]struct {
+		server DNSServer
+		want   sbDNSServer
+	}{
+		"udp": {DNSServer{Tag: "a", Type: "udp", Address: "1.1.1.1"},
+			sbDNSServer{Tag: "a", Type: "udp", Server: "1.1.1.1"}},
+		"port": {DNSServer{Tag: "a", Type: "tcp", Address: "1.1.1.1:5353", Detour: "proxy"},
+			sbDNSServer{Tag: "a", Type: "tcp", Server: "1.1.1.1", ServerPort: 5353, Detour: "proxy"}},
+		"doh": {DNSServer{Tag: "a", Type: "https", Address: "https://dns.google/dns-query"},
+			sbDNSServer{Tag: "a", Type: "https", Server: "dns.google", Path: "/dns-query", DomainResolver: "local_dns"}},
+		"ipv6": {DNSServer{Tag: "a", Type: "tls", Address: "[2606:4700:4700::1111]:853", Detour: "direct"},
+			sbDNSServer{Tag: "a", Type: "tls", Server: "2606:4700:4700::1111"}},
+	}
+	for name, c := range cases {
+		got, err := c.server.build()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %+v, want %+v", name, got, c.want)
+		}
+	}
+
+	for name, s := range map[string]DNSServer{
+		"type":     {Tag: "a", Type: "doh", Address: "1.1.1.1"},
+		"path":     {Tag: "a", Type: "tls", Address: "dns.google/dns-query"},
+		"port":     {Tag: "a", Type: "udp", Address: "1.1.1.1:70000"},
+		"no host":  {Tag: "a", Type: "udp", Address: ":53"},
+		"no input": {Tag: "a", Type: "udp"},
+	} {
+		if _, err := s.build(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDNSSettingsValidate(t *testing.T) {
+	if err := defaultDNSSettings().withDefaults().validate(); err != nil {
+		t.Errorf("defaults: %v", err)
+	}
+
+	server := DNSServer{Tag: "remote", Type: "udp", Address: "8.8.8.8"}
+	for name, d := range map[string]DNSSettings{
+		"no tag":       {Servers: []DNSServer{{Type: "udp", Address: "8.8.8.8"}}},
+		"duplicate":    {Servers: []DNSServer{server, server}},
+		"reserved":     {Servers: []DNSServer{{Tag: "local_dns", Type: "udp", Address: "8.8.8.8"}}},
+		"region":       {Servers: []DNSServer{{Tag: "ru_dns", Type: "udp", Address: "8.8.8.8"}}},
+		"final":        {Servers: []DNSServer{server}, Final: "other"},
+		"rule server":  {Servers: []DNSServer{server}, Rules: []DNSRule{{Domains: []string{"lan"}, Server: "other"}}},
+		"rule domains": {Servers: []DNSServer{server}, Rules: []DNSRule{{Server: "remote"}}},
+		"strategy":     {Servers: []DNSServer{server}, Strategy: "ipv4_first"},
+		"cache":        {Servers: []DNSServer{server}, CacheCapacity: -1},
+	} {
+		if err := d.validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// A rule may always send queries to the system resolver.
+	d := DNSSettings{Servers: []DNSServer{server}, Rules: []DNSRule{{Domains: []string{"lan"}, Server: "local_dns"}}}
+	if err := d.validate(); err != nil {
+		t.Errorf("local_dns rule: %v", err)
+	}
+
+	// Falling back to the default servers keeps the other options.
+	d = DNSSettings{Strategy: "prefer_ipv6", CacheCapacity: 4096}.withDefaults()
+	if len(d.Servers) != 1 || d.Final != "remote_dns" || d.Strategy != "prefer_ipv6" || d.CacheCapacity != 4096 {
+		t.Errorf("defaults dropped options: %+v", d)
+	}
+}
+
+func TestFakeIPRanges(t *testing.T) {
+	for _, c := range []struct {
+		cidr string
+		v6   bool
+		ok   bool
+	}{
+		{"198.18.0.0/15", false, true},
+		{"fc00::/18", true, true},
+		{"fc00::/18", false, false},
+		{"198.18.0.0/15", true, false},
+		{"198.18.0.0", false, false},
+	} {
+		if err := checkFakeIPRange(c.cidr, c.v6); (err == nil) != c.ok {
+			t.Errorf("%s (v6 %v): err = %v", c.cidr, c.v6, err)
+		}
+	}
+
+	d := defaultDNSSettings()
+	d.FakeIP = FakeIPSettings{Enabled: true, Inet4Range: "fc00::/18"}
+	if err := d.withDefaults().validate(); err == nil {
+		t.Error("expected an error for an IPv6 inet4 range")
+	}
+}
+
+func TestUseFakeIP(t *testing.T) {
+	s := Settings{RunMode: "proxy", DNS: DNSSettings{FakeIP: FakeIPSettings{Enabled: true}}}
+	if newConfigBuilder(s).useFakeIP() {
+		t.Error("FakeIP used in proxy mode")
+	}
+	s.RunMode = "tun"
+	if !newConfigBuilder(s).useFakeIP() {
+		t.Error("FakeIP not used in TUN mode")
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type FailoverGroup struct {
+	ProfileIDs     []string `json:"profile_ids,omitempty"`
+	SubscriptionID string   `json:"subscription_id,omitempty"`
+	Tag            string   `json:"tag,omitempty"`
+
+	URL       string `json:"url,omitempty"`
+	Interval  string `json:"interval,omitempty"`
+	Tolerance int    `json:"tolerance,omitempty"`
+}
+
+func (g FailoverGroup) withDefaults() FailoverGroup {
+	if g.URL == "" {
+		g.URL = "https://www.gstatic.com/generate_204"
+	}
+	if g.Interval == "" {
+		g.Interval = "3m"
+	}
+	if g.Tolerance == 0 {
+		g.Tolerance = 50
+	}
+	return g
+}
+
+func (g FailoverGroup) validate() error {
+	if len(g.ProfileIDs) == 0 && g.SubscriptionID == "" && g.Tag == "" {
+		return fmt.Errorf("failover group has no profiles, subscription or tag")
+	}
+	if g.URL != "" && !strings.HasPrefix(g.URL, "http://") && !strings.HasPrefix(g.URL, "https://") {
+		return fmt.Errorf("test URL must be http or https")
+	}
+	if g.Interval != "" {
+		if d, err := time.ParseDuration(g.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid test interval %q", g.Interval)
+		}
+	}
+	if g.Tolerance < 0 {
+		return fmt.Errorf("tolerance must not be negative")
+	}
+	return nil
+}
+
+func (g FailoverGroup) includes(p Profile) bool {
+	if g.SubscriptionID != "" && p.SubscriptionID == g.SubscriptionID {
+		return true
+	}
+	if g.Tag != "" {
+		for _, t := range p.Tags {
+			if strings.EqualFold(t, g.Tag) {
+				return true
+			}
+		}
+	}
+	for _, id := range g.ProfileIDs {
+		if id == p.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func memberTag(p Profile) string {
+	return "proxy-" + p.ID
+}
+
+// Profiles that members detour through are added as upstream members.
+func (a *App) groupMembers(g FailoverGroup) []groupMember {
+	members := []groupMember{}
+	chained := []Profile{}
+	included := map[string]bool{}
+	for _, p := range a.Profiles {
+		if !g.includes(p) || p.Outbound == nil {
+			continue
+		}
+		members = append(members, profileMember(p))
+		included[p.ID] = true
+		if p.DetourID != "" {
+			chained = append(chained, p)
+		}
+	}
+
+	for _, p := range chained {
+		chain, err := a.detourChain(p)
+		if err != nil {
+			continue // reported when the member is built
+		}
+		for _, up := range chain[1:] {
+			if included[up.ID] || up.Outbound == nil {
+				continue
+			}
+			upstream := profileMember(up)
+			upstream.Upstream = true
+			members = append(members, upstream)
+			included[up.ID] = true
+		}
+	}
+	return members
+}
+
+func profileMember(p Profile) groupMember {
+	m := groupMember{
+		Tag:      memberTag(p),
+		Name:     p.Name,
+		Outbound: p.Outbound.withOptions(p.Options),
+	}
+	if p.DetourID != "" {
+		m.Detour = memberTag(Profile{ID: p.DetourID})
+	}
+	return m
+}
+
+func (a *App) generateFailoverConfig(g FailoverGroup) (string, error) {
+	if err := g.validate(); err != nil {
+		return "", err
+	}
+	b := a.builder()
+	config, err := b.buildURLTest(a.groupMembers(g), g)
+	for _, s := range b.skipped {
+		a.log("Failover: skipped " + s)
+	}
+	if err != nil {
+		return "", err
+	}
+	return a.marshalConfig(config)
+}
+
+func (a *App) StartFailover() string {
+	if msg := a.prepareCore(); msg != "" {
+		return msg
+	}
+
+	a.cmdLock.Lock()
+	if a.proxyCmd != nil {
+		a.cmdLock.Unlock()
+		return "Already running"
+	}
+	a.cmdLock.Unlock()
+
+	return a.runCore(func() (string, error) {
+		return a.generateFailoverConfig(a.settingsSnapshot().Failover)
+	})
+}
+
+func (a *App) SetProfileTags(id string, tags []string) string {
+	clean := []string{}
+	for _, t := range tags {
+		if t = strings.TrimSpace(t); t != "" {
+			clean = append(clean, t)
+		}
+	}
+	for i, p := range a.Profiles {
+		if p.ID != id {
+			continue
+		}
+		a.Profiles[i].Tags = clean
+		if err := a.SaveProfiles(); err != nil {
+			return "Save failed: " + err.Error()
+		}
+		return "OK"
+	}
+	return "Profile not found"
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestFailoverGroupValidate(t *testing.T) {
+	if err := (FailoverGroup{Tag: "fast"}).validate(); err != nil {
+		t.Errorf("tag only: %v", err)
+	}
+	for name, g := range map[string]FailoverGroup{
+		"empty":      {},
+		"ftp url":    {Tag: "fast", URL: "ftp://example.com"},
+		"bad period": {Tag: "fast", Interval: "often"},
+		"zero":       {Tag: "fast", Interval: "0s"},
+		"tolerance":  {Tag: "fast", Tolerance: -1},
+	} {
+		if err := g.validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	g := FailoverGroup{Tag: "fast"}.withDefaults()
+	if g.URL == "" || g.Interval != "3m" || g.Tolerance != 50 {
+		t.Errorf("defaults not applied: %+v", g)
+	}
+}
+
+func TestFailoverGroupIncludes(t *testing.T) {
+	g := FailoverGroup{ProfileIDs: []string{"1"}, SubscriptionID: "sub", Tag: "Fast"}
+	for _, c := range []struct {
+		p    Profile
+		want bool
+	}{
+		{Profile{ID: "1"}, true},
+		{Profile{ID: "2", SubscriptionID: "sub"}, true},
+		{Profile{ID: "3", Tags: []string{"eu", "fast"}}, true},
+		{Profile{ID: "4", SubscriptionID: "other", Tags: []string{"slow"}}, false},
+	} {
+		if got := g.includes(c.p); got != c.want {
+			t.Errorf("profile %s: includes = %v, want %v", c.p.ID, got, c.want)
+		}
+	}
+}
@@ -1,18 +1,23 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/binary"
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/crypto/blake2b"
 	"golang.org/x/net/proxy"
 )
 
 func (a *App) UrlTest(profileID string) int {
 	a.cmdLock.Lock()
 	isRunning := a.proxyCmd != nil
-	currentSettings := a.Settings
+	currentSettings := a.settingsSnapshot()
 	a.cmdLock.Unlock()
 
 	if isRunning {
@@ -45,5 +50,130 @@ func (a *App) UrlTest(profileID string) int {
 		return int(time.Since(start).Milliseconds())
 	}
 
+	if ob := a.profileOutbound(profileID); ob != nil {
+		if ob.Protocol == "wireguard" {
+			wailsRuntime.EventsEmit(a.ctx, "log", "Ping: WireGuard profiles can only be tested while connected")
+			return -1
+		}
+		if ob.isQuic() {
+			return a.QuicPing(profileID)
+		}
+	}
 	return a.TcpPing(profileID)
 }
+
+// QUIC servers don't listen on TCP, so QuicPing times the Version Negotiation
+// packet every RFC 9000 server answers a reserved version with.
+func (a *App) QuicPing(profileID string) int {
+	ob := a.profileOutbound(profileID)
+	if ob == nil {
+		wailsRuntime.EventsEmit(a.ctx, "log", "Ping: Profile not found")
+		return -1
+	}
+	if ob.Server == "" || ob.Port == 0 {
+		wailsRuntime.EventsEmit(a.ctx, "log", "Ping: Not supported for this profile type")
+		return -1
+	}
+
+	var obfsPassword string
+	if hy := ob.Hysteria2; hy != nil && hy.ObfsType == "salamander" {
+		obfsPassword = hy.ObfsPassword
+	}
+
+	target := net.JoinHostPort(ob.Server, strconv.Itoa(ob.Port))
+
+	var rtt time.Duration
+	var pingErr error
+	for i := 0; i < 2; i++ {
+		rtt, pingErr = quicVersionProbe(target, obfsPassword, 3*time.Second)
+		if pingErr == nil {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	if pingErr != nil {
+		wailsRuntime.EventsEmit(a.ctx, "log", fmt.Sprintf("Ping: QUIC probe failed to %s: %v", target, pingErr))
+		return -1
+	}
+	return int(rtt.Milliseconds())
+}
+
+const (
+	quicMinInitialSize = 1200
+	salamanderSaltLen  = 8
+)
+
+func quicVersionProbe(target, obfsPassword string, timeout time.Duration) (time.Duration, error) {
+	conn, err := net.DialTimeout("udp", target, timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	packet := make([]byte, quicMinInitialSize)
+	if _, err := rand.Read(packet); err != nil {
+		return 0, err
+	}
+	// A version from the reserved 0x?a?a?a?a space, which no server implements.
+	packet[0] = 0xc0 | packet[0]&0x0f
+	binary.BigEndian.PutUint32(packet[1:5], 0x1a2a3a4a)
+	packet[5] = 8
+	packet[14] = 8
+	scid := append([]byte(nil), packet[15:23]...)
+
+	if obfsPassword != "" {
+		packet = salamanderObfuscate(packet, obfsPassword)
+	}
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	start := time.Now()
+	if _, err := conn.Write(packet); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 2048)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return 0, err
+		}
+		resp := buf[:n]
+		if obfsPassword != "" {
+			if resp, err = salamanderDeobfuscate(resp, obfsPassword); err != nil {
+				continue
+			}
+		}
+		if len(resp) < 7 || resp[0]&0x80 == 0 || binary.BigEndian.Uint32(resp[1:5]) != 0 {
+			continue
+		}
+		if dcidLen := int(resp[5]); dcidLen != len(scid) || len(resp) < 6+dcidLen || string(resp[6:6+dcidLen]) != string(scid) {
+			continue
+		}
+		return time.Since(start), nil
+	}
+}
+
+// salamander: a random salt, then the payload XORed with
+// BLAKE2b-256(password || salt).
+func salamanderObfuscate(payload []byte, password string) []byte {
+	out := make([]byte, salamanderSaltLen+len(payload))
+	rand.Read(out[:salamanderSaltLen])
+	key := blake2b.Sum256(append([]byte(password), out[:salamanderSaltLen]...))
+	for i, c := range payload {
+		out[salamanderSaltLen+i] = c ^ key[i%len(key)]
+	}
+	return out
+}
+
+func salamanderDeobfuscate(packet []byte, password string) ([]byte, error) {
+	if len(packet) <= salamanderSaltLen {
+		return nil, fmt.Errorf("short salamander packet")
+	}
+	key := blake2b.Sum256(append([]byte(password), packet[:salamanderSaltLen]...))
+	out := make([]byte, len(packet)-salamanderSaltLen)
+	for i, c := range packet[salamanderSaltLen:] {
+		out[i] = c ^ key[i%len(key)]
+	}
+	return out, nil
+}
@@ -0,0 +1,568 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var supportedSchemes = []string{
+	"vless://",
+	"trojan://",
+	"ss://",
+	"vmess://",
+	"hysteria2://",
+	"hy2://",
+	"tuic://",
+}
+
+var shadowsocksMethods = map[string]bool{
+	"2022-blake3-aes-128-gcm":       true,
+	"2022-blake3-aes-256-gcm":       true,
+	"2022-blake3-chacha20-poly1305": true,
+	"none":                          true,
+	"aes-128-gcm":                   true,
+	"aes-192-gcm":                   true,
+	"aes-256-gcm":                   true,
+	"chacha20-ietf-poly1305":        true,
+	"xchacha20-ietf-poly1305":       true,
+	"aes-128-ctr":                   true,
+	"aes-192-ctr":                   true,
+	"aes-256-ctr":                   true,
+	"aes-128-cfb":                   true,
+	"aes-192-cfb":                   true,
+	"aes-256-cfb":                   true,
+	"rc4-md5":                       true,
+	"chacha20-ietf":                 true,
+	"xchacha20":                     true,
+}
+
+var shadowsocksPlugins = map[string]string{
+	"obfs-local":   "obfs-local",
+	"simple-obfs":  "obfs-local",
+	"v2ray-plugin": "v2ray-plugin",
+}
+
+func isSupportedLink(link string) bool {
+	for _, scheme := range supportedSchemes {
+		if strings.HasPrefix(link, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// Legacy all-base64 ss:// links cannot be handled by url.Parse.
+func normalizeLink(link string) string {
+	if strings.HasPrefix(link, "ss://") {
+		if ob, name, err := parseShadowsocksLink(link); err == nil {
+			return shadowsocksLink(ob, name)
+		}
+	}
+	return link
+}
+
+func fieldError(protocol, field, format string, args ...interface{}) error {
+	e := &LinkError{Protocol: protocol}
+	e.add(field, format, args...)
+	return e
+}
+
+func decodeBase64Loose(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding,
+		base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		if data, err := enc.DecodeString(s); err == nil {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid base64")
+}
+
+func linkName(u *url.URL) string {
+	name, _ := url.QueryUnescape(u.Fragment)
+	return name
+}
+
+func linkPort(u *url.URL, fallback int) int {
+	if u.Port() == "" {
+		return fallback
+	}
+	port, _ := strconv.Atoi(u.Port())
+	return port
+}
+
+func boolParam(q url.Values, names ...string) bool {
+	for _, name := range names {
+		if v := q.Get(name); v == "1" || v == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+func firstParam(q url.Values, names ...string) string {
+	for _, name := range names {
+		if v := q.Get(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func splitParam(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+func tlsFromQuery(q url.Values, security string) *TLSOptions {
+	if security != "tls" && security != "reality" {
+		return nil
+	}
+	t := &TLSOptions{
+		Reality:     security == "reality",
+		ServerName:  q.Get("sni"),
+		Insecure:    boolParam(q, "allowInsecure", "insecure"),
+		ALPN:        splitParam(q.Get("alpn")),
+		Fingerprint: q.Get("fp"),
+		PublicKey:   q.Get("pbk"),
+		ShortID:     q.Get("sid"),
+	}
+
+	// ech is a base64 ECHConfigList or, in the Xray form
+	// "name+https://doh/dns-query", the name whose HTTPS record carries it.
+	if ech := q.Get("ech"); ech != "" {
+		t.ECH = true
+		if raw, err := decodeBase64Loose(ech); err == nil && len(raw) > 8 {
+			t.ECHConfig = ech
+		} else if name, _, _ := strings.Cut(ech, "+"); name != "1" && name != "true" {
+			t.ECHQueryServerName = name
+		}
+	}
+
+	if boolParam(q, "fragment") || boolParam(q, "record_fragment") {
+		t.Fragment = &FragmentOptions{
+			Fragment:       boolParam(q, "fragment"),
+			RecordFragment: boolParam(q, "record_fragment"),
+			FallbackDelay:  q.Get("fragment_fallback_delay"),
+		}
+	}
+	return t
+}
+
+func muxFromQuery(q url.Values) *MultiplexOptions {
+	protocol := q.Get("mux")
+	if protocol == "" || protocol == "0" || protocol == "false" {
+		return nil
+	}
+	if protocol == "1" || protocol == "true" {
+		protocol = ""
+	}
+	mux := &MultiplexOptions{Enabled: true, Protocol: protocol, Padding: boolParam(q, "mux_padding")}
+	mux.MaxConnections, _ = strconv.Atoi(q.Get("mux_max_connections"))
+	mux.MinStreams, _ = strconv.Atoi(q.Get("mux_min_streams"))
+	mux.MaxStreams, _ = strconv.Atoi(q.Get("mux_max_streams"))
+	mux.BrutalUpMbps, _ = strconv.Atoi(q.Get("mux_up"))
+	mux.BrutalDownMbps, _ = strconv.Atoi(q.Get("mux_down"))
+	return mux
+}
+
+// splithttp is the old name of xhttp, which is only recognised so validate
+// can reject it by name.
+var transportTypes = map[string]string{
+	"ws":          "ws",
+	"grpc":        "grpc",
+	"http":        "http",
+	"h2":          "http",
+	"httpupgrade": "httpupgrade",
+	"quic":        "quic",
+	"xhttp":       "xhttp",
+	"splithttp":   "xhttp",
+}
+
+func transportFromQuery(q url.Values) *TransportOptions {
+	transportType := q.Get("type")
+	if transportType == "" || transportType == "tcp" || transportType == "raw" {
+		return nil
+	}
+	if mapped, ok := transportTypes[transportType]; ok {
+		transportType = mapped
+	}
+
+	t := &TransportOptions{
+		Type:        transportType,
+		Path:        q.Get("path"),
+		Host:        q.Get("host"),
+		ServiceName: q.Get("serviceName"),
+		Mode:        q.Get("mode"),
+	}
+
+	if t.Type == "ws" {
+		// Xray clients put early data into the path as "/ws?ed=2048".
+		if path, query, ok := strings.Cut(t.Path, "?"); ok {
+			if pq, err := url.ParseQuery(query); err == nil && pq.Get("ed") != "" {
+				t.Path = path
+				if q.Get("ed") == "" {
+					q.Set("ed", pq.Get("ed"))
+				}
+			}
+		}
+		t.MaxEarlyData, _ = strconv.Atoi(q.Get("ed"))
+		t.EarlyDataHeader = q.Get("eh")
+		if t.MaxEarlyData > 0 && t.EarlyDataHeader == "" {
+			t.EarlyDataHeader = "Sec-WebSocket-Protocol"
+		}
+	}
+	return t
+}
+
+func parseVlessLink(link string) (*Outbound, string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, "", fieldError("vless", "key", "bad link: %v", err)
+	}
+	q := u.Query()
+
+	ob := &Outbound{
+		Protocol: "vless",
+		Server:   u.Hostname(),
+		Port:     linkPort(u, 0),
+		Credentials: Credentials{
+			UUID: u.User.Username(),
+			Flow: q.Get("flow"),
+		},
+		TLS:       tlsFromQuery(q, q.Get("security")),
+		Transport: transportFromQuery(q),
+		Multiplex: muxFromQuery(q),
+	}
+	return ob, linkName(u), nil
+}
+
+func parseTrojanLink(link string) (*Outbound, string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, "", fieldError("trojan", "key", "bad link: %v", err)
+	}
+	q := u.Query()
+
+	security := q.Get("security")
+	if security == "" {
+		security = "tls"
+	}
+	if q.Get("sni") == "" && q.Get("peer") != "" {
+		q.Set("sni", q.Get("peer"))
+	}
+
+	ob := &Outbound{
+		Protocol:    "trojan",
+		Server:      u.Hostname(),
+		Port:        linkPort(u, 443),
+		Credentials: Credentials{Password: u.User.Username()},
+		TLS:         tlsFromQuery(q, security),
+		Transport:   transportFromQuery(q),
+		Multiplex:   muxFromQuery(q),
+	}
+	return ob, linkName(u), nil
+}
+
+func parseShadowsocksLink(link string) (*Outbound, string, error) {
+	rest := strings.TrimPrefix(link, "ss://")
+
+	name := ""
+	if idx := strings.Index(rest, "#"); idx != -1 {
+		name, _ = url.PathUnescape(rest[idx+1:])
+		rest = rest[:idx]
+	}
+
+	if !strings.Contains(rest, "@") {
+		decoded, err := decodeBase64Loose(rest)
+		if err != nil {
+			return nil, "", fieldError("shadowsocks", "key", "bad ss link")
+		}
+		rest = string(decoded)
+	}
+
+	u, err := url.Parse("ss://" + rest)
+	if err != nil || u.User == nil {
+		return nil, "", fieldError("shadowsocks", "key", "bad ss link")
+	}
+
+	var method, password string
+	if pass, ok := u.User.Password(); ok {
+		method, password = u.User.Username(), pass
+	} else {
+		decoded, err := decodeBase64Loose(u.User.Username())
+		if err != nil {
+			return nil, "", fieldError("shadowsocks", "userinfo", "userinfo is not base64")
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return nil, "", fieldError("shadowsocks", "userinfo", "userinfo misses method:password")
+		}
+		method, password = parts[0], parts[1]
+	}
+
+	method = strings.ToLower(method)
+	if method == "chacha20-poly1305" {
+		method = "chacha20-ietf-poly1305"
+	}
+
+	ob := &Outbound{
+		Protocol: "shadowsocks",
+		Server:   u.Hostname(),
+		Port:     linkPort(u, 0),
+		Credentials: Credentials{
+			Method:   method,
+			Password: password,
+		},
+	}
+
+	ob.Multiplex = muxFromQuery(u.Query())
+
+	if plugin := u.Query().Get("plugin"); plugin != "" {
+		parts := strings.SplitN(plugin, ";", 2)
+		mapped, ok := shadowsocksPlugins[parts[0]]
+		if !ok {
+			return nil, "", fieldError("shadowsocks", "plugin", "unsupported plugin %q", parts[0])
+		}
+		ob.Shadowsocks = &ShadowsocksOptions{Plugin: mapped}
+		if len(parts) == 2 {
+			ob.Shadowsocks.PluginOpts = parts[1]
+		}
+	}
+
+	return ob, name, nil
+}
+
+// v2rayN and its clones disagree on whether port and aid are quoted.
+type flexString string
+
+func (f *flexString) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*f = flexString(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*f = flexString(n.String())
+	return nil
+}
+
+type vmessLink struct {
+	V    flexString `json:"v"`
+	Ps   string     `json:"ps"`
+	Add  string     `json:"add"`
+	Port flexString `json:"port"`
+	ID   string     `json:"id"`
+	Aid  flexString `json:"aid"`
+	Scy  string     `json:"scy"`
+	Net  string     `json:"net"`
+	Type string     `json:"type"`
+	Host string     `json:"host"`
+	Path string     `json:"path"`
+	TLS  string     `json:"tls"`
+	SNI  string     `json:"sni"`
+	ALPN string     `json:"alpn"`
+	FP   string     `json:"fp"`
+}
+
+func parseVmessLink(link string) (*Outbound, string, error) {
+	data, err := decodeBase64Loose(strings.TrimPrefix(link, "vmess://"))
+	if err != nil {
+		return nil, "", fieldError("vmess", "key", "payload is not base64")
+	}
+
+	var vm vmessLink
+	if err := json.Unmarshal(data, &vm); err != nil {
+		return nil, "", fieldError("vmess", "key", "bad json: %v", err)
+	}
+
+	port, err := strconv.Atoi(string(vm.Port))
+	if err != nil && vm.Port != "" {
+		return nil, "", fieldError("vmess", "port", "port %q is not a number", vm.Port)
+	}
+	alterID, _ := strconv.Atoi(string(vm.Aid))
+
+	security := vm.Scy
+	if security == "" {
+		security = "auto"
+	}
+
+	q := vm.values()
+	ob := &Outbound{
+		Protocol: "vmess",
+		Server:   vm.Add,
+		Port:     port,
+		Credentials: Credentials{
+			UUID:    vm.ID,
+			Method:  security,
+			AlterID: alterID,
+		},
+		TLS:       tlsFromQuery(q, q.Get("security")),
+		Transport: transportFromQuery(q),
+	}
+	return ob, vm.Ps, nil
+}
+
+func (vm *vmessLink) values() url.Values {
+	q := url.Values{}
+
+	switch vm.Net {
+	case "", "tcp":
+	case "h2":
+		q.Set("type", "http")
+	default:
+		q.Set("type", vm.Net)
+	}
+
+	q.Set("host", vm.Host)
+	q.Set("path", vm.Path)
+	if vm.Net == "grpc" {
+		q.Set("serviceName", vm.Path)
+	}
+
+	q.Set("security", vm.TLS)
+	q.Set("sni", vm.SNI)
+	if vm.SNI == "" && vm.TLS == "tls" {
+		q.Set("sni", vm.Host)
+	}
+	q.Set("alpn", vm.ALPN)
+	q.Set("fp", vm.FP)
+	return q
+}
+
+// The authority may carry a port hopping spec ("443,20000-30000") which
+// url.Parse rejects.
+func parseHysteria2Link(link string) (*Outbound, string, error) {
+	body := link[strings.Index(link, "://")+3:]
+
+	authEnd := strings.IndexAny(body, "/?#")
+	if authEnd == -1 {
+		authEnd = len(body)
+	}
+	authority := body[:authEnd]
+
+	hy := &Hysteria2Options{}
+	ob := &Outbound{Protocol: "hysteria2", Hysteria2: hy}
+
+	if idx := strings.LastIndex(authority, "@"); idx != -1 {
+		ob.Credentials.Password, _ = url.PathUnescape(authority[:idx])
+		authority = authority[idx+1:]
+	}
+
+	host, portSpec := authority, ""
+	if strings.HasPrefix(host, "[") {
+		end := strings.Index(host, "]")
+		if end == -1 {
+			return nil, "", fieldError("hysteria2", "server", "unterminated IPv6 address")
+		}
+		host, portSpec = authority[1:end], strings.TrimPrefix(authority[end+1:], ":")
+	} else if idx := strings.Index(host, ":"); idx != -1 {
+		host, portSpec = authority[:idx], authority[idx+1:]
+	}
+	ob.Server = host
+
+	u, err := url.Parse("hy2://placeholder" + body[authEnd:])
+	if err != nil {
+		return nil, "", fieldError("hysteria2", "key", "bad link: %v", err)
+	}
+	q := u.Query()
+
+	if portSpec == "" {
+		portSpec = "443"
+	}
+	if mport := q.Get("mport"); mport != "" {
+		portSpec += "," + mport
+	}
+	for _, part := range strings.Split(portSpec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to, err := parsePortRange(part)
+		if err != nil {
+			return nil, "", fieldError("hysteria2", "port", "%v", err)
+		}
+		if from != to || ob.Port != 0 {
+			hy.Ports = append(hy.Ports, fmt.Sprintf("%d:%d", from, to))
+		}
+		if ob.Port == 0 {
+			ob.Port = from
+		}
+	}
+
+	if obfs := q.Get("obfs"); obfs != "" && obfs != "none" {
+		if obfs != "salamander" {
+			return nil, "", fieldError("hysteria2", "obfs", "unsupported obfs %q", obfs)
+		}
+		hy.ObfsType = obfs
+		hy.ObfsPassword = q.Get("obfs-password")
+	}
+
+	hy.UpMbps, _ = strconv.Atoi(firstParam(q, "upmbps", "up"))
+	hy.DownMbps, _ = strconv.Atoi(firstParam(q, "downmbps", "down"))
+
+	ob.TLS = &TLSOptions{
+		ServerName: q.Get("sni"),
+		Insecure:   boolParam(q, "insecure", "allowInsecure"),
+		ALPN:       splitParam(q.Get("alpn")),
+	}
+
+	name, _ := url.PathUnescape(u.Fragment)
+	return ob, name, nil
+}
+
+func parseTuicLink(link string) (*Outbound, string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, "", fieldError("tuic", "key", "bad link: %v", err)
+	}
+	q := u.Query()
+	password, _ := u.User.Password()
+
+	congestion := firstParam(q, "congestion_control", "congestion")
+	if congestion == "" {
+		congestion = "bbr"
+	}
+	relayMode := q.Get("udp_relay_mode")
+	if relayMode == "" {
+		relayMode = "native"
+	}
+
+	alpn := splitParam(q.Get("alpn"))
+	if len(alpn) == 0 {
+		alpn = []string{"h3"}
+	}
+
+	ob := &Outbound{
+		Protocol: "tuic",
+		Server:   u.Hostname(),
+		Port:     linkPort(u, 443),
+		Credentials: Credentials{
+			UUID:     u.User.Username(),
+			Password: password,
+		},
+		TLS: &TLSOptions{
+			ServerName: q.Get("sni"),
+			Insecure:   boolParam(q, "allow_insecure", "allowInsecure", "insecure"),
+			ALPN:       alpn,
+			DisableSNI: boolParam(q, "disable_sni"),
+		},
+		TUIC: &TUICOptions{
+			CongestionControl: congestion,
+			UDPRelayMode:      relayMode,
+			ZeroRTT:           boolParam(q, "reduce_rtt", "zero_rtt_handshake"),
+		},
+	}
+	return ob, linkName(u), nil
+}
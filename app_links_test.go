@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestXHTTPLinksRejected(t *testing.T) {
+	for _, transport := range []string{"xhttp", "splithttp"} {
+		_, _, err := parseProfileKey(goldenVlessLink(transport, "tls"))
+		var linkErr *LinkError
+		if !errors.As(err, &linkErr) {
+			t.Fatalf("%s: want a LinkError, got %v", transport, err)
+		}
+		found := false
+		for _, f := range linkErr.Fields {
+			found = found || f.Field == "type"
+		}
+		if !found {
+			t.Errorf("%s: no error on the type field: %v", transport, err)
+		}
+	}
+}
+
+func TestHysteria2PortHopping(t *testing.T) {
+	ob, _, err := parseProfileKey("hy2://pass@hy.example.com:443,8443-8450?mport=9000#Hy2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ob.Port != 443 || fmt.Sprint(ob.Hysteria2.Ports) != "[8443:8450 9000:9000]" {
+		t.Errorf("port %d, hopping ports %v", ob.Port, ob.Hysteria2.Ports)
+	}
+
+	for _, link := range []string{
+		"hy2://pass@hy.example.com:0-99999#Hy2",
+		"hy2://pass@hy.example.com:443,70000#Hy2",
+		"hy2://pass@hy.example.com:443?mport=9000-8000#Hy2",
+		"hy2://pass@hy.example.com:443,http#Hy2",
+	} {
+		if _, _, err := parseProfileKey(link); err == nil {
+			t.Errorf("%s: expected an error", link)
+		}
+	}
+
+	ob.Hysteria2.Ports = []string{"8443:99999"}
+	if err := ob.validate(); err == nil {
+		t.Error("validate accepted an out-of-range hopping port")
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outbound is stored next to the link so nothing has to re-parse it.
+type Outbound struct {
+	Protocol    string            `json:"protocol"`
+	Server      string            `json:"server"`
+	Port        int               `json:"port"`
+	Credentials Credentials       `json:"credentials"`
+	TLS         *TLSOptions       `json:"tls,omitempty"`
+	Transport   *TransportOptions `json:"transport,omitempty"`
+	Multiplex   *MultiplexOptions `json:"multiplex,omitempty"`
+
+	Shadowsocks *ShadowsocksOptions `json:"shadowsocks,omitempty"`
+	Hysteria2   *Hysteria2Options   `json:"hysteria2,omitempty"`
+	TUIC        *TUICOptions        `json:"tuic,omitempty"`
+	WireGuard   *WireGuardOptions   `json:"wireguard,omitempty"`
+
+	// Raw is a literal sing-box outbound; Protocol, Server and Port mirror it.
+	Raw json.RawMessage `json:"raw,omitempty"`
+}
+
+type Credentials struct {
+	UUID     string `json:"uuid,omitempty"`
+	Password string `json:"password,omitempty"`
+	Method   string `json:"method,omitempty"`
+	AlterID  int    `json:"alter_id,omitempty"`
+	Flow     string `json:"flow,omitempty"`
+}
+
+type TLSOptions struct {
+	Reality     bool     `json:"reality,omitempty"`
+	ServerName  string   `json:"server_name,omitempty"`
+	Insecure    bool     `json:"insecure,omitempty"`
+	ALPN        []string `json:"alpn,omitempty"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+	PublicKey   string   `json:"public_key,omitempty"`
+	ShortID     string   `json:"short_id,omitempty"`
+	DisableSNI  bool     `json:"disable_sni,omitempty"`
+
+	ECH                bool   `json:"ech,omitempty"`
+	ECHConfig          string `json:"ech_config,omitempty"`
+	ECHQueryServerName string `json:"ech_query_server_name,omitempty"`
+
+	Fragment *FragmentOptions `json:"fragment,omitempty"`
+}
+
+type FragmentOptions struct {
+	Fragment       bool   `json:"fragment,omitempty"`
+	RecordFragment bool   `json:"record_fragment,omitempty"`
+	FallbackDelay  string `json:"fallback_delay,omitempty"`
+}
+
+type TransportOptions struct {
+	Type        string `json:"type"`
+	Path        string `json:"path,omitempty"`
+	Host        string `json:"host,omitempty"`
+	ServiceName string `json:"service_name,omitempty"`
+
+	MaxEarlyData    int    `json:"max_early_data,omitempty"`
+	EarlyDataHeader string `json:"early_data_header,omitempty"`
+	Mode            string `json:"mode,omitempty"`
+}
+
+type MultiplexOptions struct {
+	Enabled        bool   `json:"enabled"`
+	Protocol       string `json:"protocol,omitempty"`
+	MaxConnections int    `json:"max_connections,omitempty"`
+	MinStreams     int    `json:"min_streams,omitempty"`
+	MaxStreams     int    `json:"max_streams,omitempty"`
+	Padding        bool   `json:"padding,omitempty"`
+	BrutalUpMbps   int    `json:"brutal_up_mbps,omitempty"`
+	BrutalDownMbps int    `json:"brutal_down_mbps,omitempty"`
+}
+
+type ShadowsocksOptions struct {
+	Plugin     string `json:"plugin,omitempty"`
+	PluginOpts string `json:"plugin_opts,omitempty"`
+}
+
+type Hysteria2Options struct {
+	Ports        []string `json:"ports,omitempty"`
+	ObfsType     string   `json:"obfs_type,omitempty"`
+	ObfsPassword string   `json:"obfs_password,omitempty"`
+	UpMbps       int      `json:"up_mbps,omitempty"`
+	DownMbps     int      `json:"down_mbps,omitempty"`
+}
+
+type TUICOptions struct {
+	CongestionControl string `json:"congestion_control"`
+	UDPRelayMode      string `json:"udp_relay_mode"`
+	ZeroRTT           bool   `json:"zero_rtt,omitempty"`
+}
+
+type WireGuardOptions struct {
+	PrivateKey string          `json:"private_key"`
+	Addresses  []string        `json:"addresses"`
+	MTU        int             `json:"mtu,omitempty"`
+	Peers      []WireGuardPeer `json:"peers"`
+}
+
+type WireGuardPeer struct {
+	Server       string   `json:"server"`
+	Port         int      `json:"port"`
+	PublicKey    string   `json:"public_key"`
+	PresharedKey string   `json:"preshared_key,omitempty"`
+	AllowedIPs   []string `json:"allowed_ips"`
+	Keepalive    int      `json:"keepalive,omitempty"`
+}
+
+// Field uses the link parameter name where there is one ("pbk", "port").
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type LinkError struct {
+	Protocol string
+	Fields   []FieldError
+}
+
+func (e *LinkError) add(field, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *LinkError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Message)
+	}
+	return fmt.Sprintf("invalid %s link: %s", e.Protocol, strings.Join(msgs, "; "))
+}
+
+func (e *LinkError) errOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (ob *Outbound) isQuic() bool {
+	if ob.Transport != nil && ob.Transport.Type == "quic" {
+		return true
+	}
+	return ob.Protocol == "hysteria2" || ob.Protocol == "tuic"
+}
+
+// Parsers call validate, so a stored Outbound is always complete.
+func (ob *Outbound) validate() error {
+	if ob.Raw != nil {
+		return ob.validateRaw()
+	}
+
+	e := &LinkError{Protocol: ob.Protocol}
+
+	if ob.WireGuard == nil {
+		if ob.Server == "" {
+			e.add("server", "server missing")
+		}
+		if ob.Port == 0 {
+			e.add("port", "port missing")
+		} else if ob.Port < 0 || ob.Port > 65535 {
+			e.add("port", "port %d out of range", ob.Port)
+		}
+	}
+
+	switch ob.Protocol {
+	case "vless", "vmess", "tuic":
+		if ob.Credentials.UUID == "" {
+			e.add("uuid", "uuid missing")
+		} else if _, err := uuid.Parse(ob.Credentials.UUID); err != nil {
+			e.add("uuid", "invalid uuid %q", ob.Credentials.UUID)
+		}
+	}
+
+	switch ob.Protocol {
+	case "trojan", "shadowsocks", "tuic":
+		if ob.Credentials.Password == "" {
+			e.add("password", "password missing")
+		}
+	}
+
+	if ob.Protocol == "shadowsocks" && !shadowsocksMethods[ob.Credentials.Method] {
+		e.add("method", "unsupported method %q", ob.Credentials.Method)
+	}
+
+	if t := ob.Transport; t != nil {
+		if _, ok := transportTypes[t.Type]; !ok {
+			e.add("type", "unsupported transport %q", t.Type)
+		}
+		// xhttp only exists in Xray; the bundled sing-box has no such transport.
+		if t.Type == "xhttp" {
+			e.add("type", "xhttp transport is not supported by sing-box")
+		}
+		if t.Type == "quic" && ob.TLS == nil {
+			e.add("security", "quic transport requires tls")
+		}
+	}
+
+	if mux := ob.Multiplex; mux != nil && mux.Enabled {
+		switch ob.Protocol {
+		case "vless", "vmess", "trojan", "shadowsocks":
+		default:
+			e.add("mux", "multiplex is not supported by %s", ob.Protocol)
+		}
+		if ob.Credentials.Flow != "" {
+			e.add("mux", "multiplex can't be combined with flow %s", ob.Credentials.Flow)
+		}
+		switch mux.Protocol {
+		case "", "smux", "yamux", "h2mux":
+		default:
+			e.add("mux", "unknown multiplex protocol %q", mux.Protocol)
+		}
+		if (mux.BrutalUpMbps > 0) != (mux.BrutalDownMbps > 0) {
+			e.add("mux_up", "brutal needs both up and down bandwidth")
+		}
+	}
+
+	if ob.TLS != nil && ob.TLS.Fragment != nil {
+		if ob.isQuic() {
+			e.add("fragment", "TLS fragmentation doesn't apply to QUIC")
+		}
+		if d := ob.TLS.Fragment.FallbackDelay; d != "" {
+			if _, err := time.ParseDuration(d); err != nil {
+				e.add("fragment_fallback_delay", "bad duration %q", d)
+			}
+		}
+	}
+
+	if ob.TLS != nil && ob.TLS.Reality && ob.TLS.PublicKey == "" {
+		e.add("pbk", "reality requires pbk")
+	}
+
+	if hy := ob.Hysteria2; hy != nil {
+		if hy.ObfsType == "salamander" && hy.ObfsPassword == "" {
+			e.add("obfs-password", "salamander requires obfs-password")
+		}
+		for _, p := range hy.Ports {
+			if _, _, err := parsePortRange(p); err != nil {
+				e.add("mport", "%v", err)
+			}
+		}
+	}
+
+	if wg := ob.WireGuard; wg != nil {
+		if wg.PrivateKey == "" {
+			e.add("PrivateKey", "PrivateKey missing")
+		}
+		if len(wg.Addresses) == 0 {
+			e.add("Address", "Address missing")
+		}
+		if len(wg.Peers) == 0 {
+			e.add("Peer", "[Peer] section missing")
+		}
+		for i, p := range wg.Peers {
+			if p.PublicKey == "" {
+				e.add("PublicKey", "peer %d: PublicKey missing", i+1)
+			}
+			if p.Server == "" || p.Port == 0 {
+				e.add("Endpoint", "peer %d: Endpoint missing", i+1)
+			}
+		}
+	}
+
+	return e.errOrNil()
+}
+
+// ProfileOptions are kept apart from the link so re-importing keeps them.
+type ProfileOptions struct {
+	Multiplex *MultiplexOptions `json:"multiplex,omitempty"`
+	Fragment  *FragmentOptions  `json:"fragment,omitempty"`
+}
+
+func (ob *Outbound) withOptions(opts *ProfileOptions) *Outbound {
+	if opts == nil || ob.Raw != nil {
+		return ob
+	}
+	out := *ob
+	if opts.Multiplex != nil {
+		out.Multiplex = opts.Multiplex
+	}
+	if opts.Fragment != nil && out.TLS != nil {
+		tls := *out.TLS
+		tls.Fragment = opts.Fragment
+		out.TLS = &tls
+	}
+	return &out
+}
+
+func parseProfileKey(key string) (*Outbound, string, error) {
+	key = strings.TrimSpace(key)
+
+	var ob *Outbound
+	var name string
+	var err error
+
+	switch {
+	case isWireGuardConfig(key):
+		ob, err = parseWireGuardConfig(key)
+	case isRawOutbound(key):
+		ob, name, err = parseRawOutbound(key)
+	case strings.HasPrefix(key, "vless://"):
+		ob, name, err = parseVlessLink(key)
+	case strings.HasPrefix(key, "trojan://"):
+		ob, name, err = parseTrojanLink(key)
+	case strings.HasPrefix(key, "ss://"):
+		ob, name, err = parseShadowsocksLink(key)
+	case strings.HasPrefix(key, "vmess://"):
+		ob, name, err = parseVmessLink(key)
+	case strings.HasPrefix(key, "hysteria2://"), strings.HasPrefix(key, "hy2://"):
+		ob, name, err = parseHysteria2Link(key)
+	case strings.HasPrefix(key, "tuic://"):
+		ob, name, err = parseTuicLink(key)
+	default:
+		return nil, "", fmt.Errorf("unsupported link")
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := ob.validate(); err != nil {
+		return nil, "", err
+	}
+	if name == "" {
+		name = ob.Server
+	}
+	return ob, name, nil
+}
+
+func (a *App) ValidateProfileKey(key string) []FieldError {
+	_, _, err := parseProfileKey(key)
+	if err == nil {
+		return []FieldError{}
+	}
+	if linkErr, ok := err.(*LinkError); ok {
+		return linkErr.Fields
+	}
+	return []FieldError{{Field: "key", Message: err.Error()}}
+}
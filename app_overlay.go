@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// An object overlay is a JSON Merge Patch (RFC 7396), an array a list of JSON
+// Patch operations (RFC 6902).
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+func applyConfigOverlay(configJSON []byte, overlay string) ([]byte, error) {
+	overlay = strings.TrimSpace(overlay)
+	if overlay == "" {
+		return configJSON, nil
+	}
+
+	doc, err := decodeConfigValue(configJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	switch overlay[0] {
+	case '{':
+		patch, err := decodeConfigValue([]byte(overlay))
+		if err != nil {
+			return nil, fmt.Errorf("invalid merge patch: %w", err)
+		}
+		doc = mergePatch(doc, patch)
+	case '[':
+		var ops []patchOperation
+		if err := json.Unmarshal([]byte(overlay), &ops); err != nil {
+			return nil, fmt.Errorf("invalid JSON patch: %w", err)
+		}
+		for i, op := range ops {
+			if doc, err = applyPatchOperation(doc, op); err != nil {
+				return nil, fmt.Errorf("patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("overlay must be a JSON object (merge patch) or array (JSON patch)")
+	}
+
+	if _, ok := doc.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("overlay must leave the config a JSON object")
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+func applyPatchOperation(doc interface{}, op patchOperation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+		if value, err = decodeConfigValue(op.Value); err != nil {
+			return nil, err
+		}
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if value, err = getPointer(doc, from); err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		// RFC 6902 4.4: a location can't be moved into one of its children.
+		if op.Op == "move" && len(from) < len(path) && reflect.DeepEqual(from, path[:len(from)]) {
+			return nil, fmt.Errorf("cannot move %s into its own child", op.From)
+		}
+		if op.Op == "move" {
+			if doc, err = removePointer(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopyValue(value)
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("unknown op %q", op.Op)
+	}
+
+	switch op.Op {
+	case "add", "move", "copy":
+		return addPointer(doc, path, value)
+	case "remove":
+		return removePointer(doc, path)
+	case "replace":
+		if len(path) == 0 {
+			return value, nil
+		}
+		if doc, err = removePointer(doc, path); err != nil {
+			return nil, err
+		}
+		return addPointer(doc, path, value)
+	default: // test
+		current, err := getPointer(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	}
+}
+
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return length, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > length || (i == length && !allowEnd) {
+		return 0, fmt.Errorf("index %q out of range", token)
+	}
+	return i, nil
+}
+
+func getPointer(doc interface{}, path []string) (interface{}, error) {
+	for _, t := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[t]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", t)
+			}
+			doc = v
+		case []interface{}:
+			i, err := arrayIndex(t, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("cannot index %q into a scalar", t)
+		}
+	}
+	return doc, nil
+}
+
+// Inserting into or removing from an array stores a new slice in its parent,
+// so the new document is returned.
+func addPointer(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := getPointer(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return doc, nil
+	case []interface{}:
+		i, err := arrayIndex(last, len(node), true)
+		if err != nil {
+			return nil, err
+		}
+		node = append(node, nil)
+		copy(node[i+1:], node[i:])
+		node[i] = value
+		return setPointer(doc, path[:len(path)-1], node)
+	}
+	return nil, fmt.Errorf("cannot add %q to a scalar", last)
+}
+
+func removePointer(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole config")
+	}
+	parent, err := getPointer(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := node[last]; !ok {
+			return nil, fmt.Errorf("key %q not found", last)
+		}
+		delete(node, last)
+		return doc, nil
+	case []interface{}:
+		i, err := arrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node = append(node[:i:i], node[i+1:]...)
+		return setPointer(doc, path[:len(path)-1], node)
+	}
+	return nil, fmt.Errorf("cannot remove %q from a scalar", last)
+}
+
+func setPointer(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := getPointer(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+	case []interface{}:
+		i, err := arrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = value
+	}
+	return doc, nil
+}
+
+func deepCopyValue(v interface{}) interface{} {
+	data, _ := json.Marshal(v)
+	out, _ := decodeConfigValue(data)
+	return out
+}
+
+func (a *App) ValidateConfigOverlay(overlay string) string {
+	placeholder := &Outbound{
+		Protocol:    "vless",
+		Server:      "example.com",
+		Port:        443,
+		Credentials: Credentials{UUID: "00000000-0000-0000-0000-000000000000"},
+	}
+
+	var config *sbConfig
+	var err error
+	if ob := a.profileOutbound(a.settingsSnapshot().LastProfileID); ob != nil {
+		config, err = a.builder().build(ob)
+	}
+	if config == nil || err != nil {
+		if config, err = a.builder().build(placeholder); err != nil {
+			return "Error: " + err.Error()
+		}
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	if _, err := applyConfigOverlay(data, overlay); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "OK"
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const overlayBase = `{"log": {"level": "info"}, "outbounds": [{"tag": "proxy"}, {"tag": "direct"}], "a/b": {"c~d": 1}}`
+
+func TestApplyConfigOverlay(t *testing.T) {
+	cases := []struct {
+		name, overlay, want string
+	}{
+		{"empty", "  ", overlayBase},
+		{"merge", `{"log": {"level": "debug", "timestamp": true}, "a/b": null}`,
+			`{"log": {"level": "debug", "timestamp": true}, "outbounds": [{"tag": "proxy"}, {"tag": "direct"}]}`},
+		{"merge replaces arrays", `{"outbounds": [{"tag": "block"}]}`,
+			`{"log": {"level": "info"}, "outbounds": [{"tag": "block"}], "a/b": {"c~d": 1}}`},
+		{"add key", `[{"op": "add", "path": "/log/output", "value": "box.log"}]`,
+			`{"log": {"level": "info", "output": "box.log"}, "outbounds": [{"tag": "proxy"}, {"tag": "direct"}], "a/b": {"c~d": 1}}`},
+		{"add index", `[{"op": "add", "path": "/outbounds/1", "value": {"tag": "block"}}]`,
+			`{"log": {"level": "info"}, "outbounds": [{"tag": "proxy"}, {"tag": "block"}, {"tag": "direct"}], "a/b": {"c~d": 1}}`},
+		{"add end", `[{"op": "add", "path": "/outbounds/-", "value": {"tag": "block"}}]`,
+			`{"log": {"level": "info"}, "outbounds": [{"tag": "proxy"}, {"tag": "direct"}, {"tag": "block"}], "a/b": {"c~d": 1}}`},
+		{"remove", `[{"op": "remove", "path": "/outbounds/0"}, {"op": "remove", "path": "/a~1b/c~0d"}]`,
+			`{"log": {"level": "info"}, "outbounds": [{"tag": "direct"}], "a/b": {}}`},
+		{"replace", `[{"op": "replace", "path": "/log/level", "value": "warn"}]`,
+			`{"log": {"level": "warn"}, "outbounds": [{"tag": "proxy"}, {"tag": "direct"}], "a/b": {"c~d": 1}}`},
+		{"move", `[{"op": "move", "from": "/outbounds/1", "path": "/outbounds/0"}]`,
+			`{"log": {"level": "info"}, "outbounds": [{"tag": "direct"}, {"tag": "proxy"}], "a/b": {"c~d": 1}}`},
+		{"move to parent", `[{"op": "move", "from": "/log/level", "path": "/level"}]`,
+			`{"log": {}, "level": "info", "outbounds": [{"tag": "proxy"}, {"tag": "direct"}], "a/b": {"c~d": 1}}`},
+		{"copy is deep", `[{"op": "copy", "from": "/log", "path": "/log2"}, {"op": "replace", "path": "/log2/level", "value": "debug"}]`,
+			`{"log": {"level": "info"}, "log2": {"level": "debug"}, "outbounds": [{"tag": "proxy"}, {"tag": "direct"}], "a/b": {"c~d": 1}}`},
+		{"test", `[{"op": "test", "path": "/outbounds/0", "value": {"tag": "proxy"}}]`, overlayBase},
+	}
+	for _, c := range cases {
+		got, err := applyConfigOverlay([]byte(overlayBase), c.overlay)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !jsonEqual(t, got, []byte(c.want)) {
+			t.Errorf("%s:\n got %s\nwant %s", c.name, compactJSON(got), c.want)
+		}
+	}
+}
+
+func TestApplyConfigOverlayErrors(t *testing.T) {
+	cases := map[string]string{
+		"scalar overlay":   `"debug"`,
+		"bad json":         `{"log": `,
+		"test failed":      `[{"op": "test", "path": "/log/level", "value": "debug"}]`,
+		"unknown op":       `[{"op": "merge", "path": "/log"}]`,
+		"missing value":    `[{"op": "add", "path": "/log/output"}]`,
+		"relative path":    `[{"op": "remove", "path": "log"}]`,
+		"remove missing":   `[{"op": "remove", "path": "/route"}]`,
+		"index range":      `[{"op": "add", "path": "/outbounds/5", "value": {}}]`,
+		"bad index":        `[{"op": "replace", "path": "/outbounds/x", "value": {}}]`,
+		"into scalar":      `[{"op": "add", "path": "/log/level/x", "value": 1}]`,
+		"missing from":     `[{"op": "copy", "from": "/dns", "path": "/dns2"}]`,
+		"move into child":  `[{"op": "move", "from": "/log", "path": "/log/inner"}]`,
+		"remove root":      `[{"op": "remove", "path": ""}]`,
+		"root not object":  `[{"op": "replace", "path": "", "value": []}]`,
+		"second op failed": `[{"op": "add", "path": "/x", "value": 1}, {"op": "test", "path": "/x", "value": 2}]`,
+	}
+	for name, overlay := range cases {
+		if _, err := applyConfigOverlay([]byte(overlayBase), overlay); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	_, err := applyConfigOverlay([]byte(overlayBase), cases["move into child"])
+	if err == nil || !strings.Contains(err.Error(), "own child") {
+		t.Errorf("move into a child should be rejected as such: %v", err)
+	}
+
+	_, err = applyConfigOverlay([]byte(overlayBase), cases["second op failed"])
+	if err == nil || !strings.Contains(err.Error(), "patch operation 1") {
+		t.Errorf("error should name the failing operation: %v", err)
+	}
+}
+
+func compactJSON(data []byte) string {
+	var v interface{}
+	json.Unmarshal(data, &v)
+	out, _ := json.Marshal(v)
+	return string(out)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ConfigPreview struct {
+	Config      string         `json:"config"`
+	HasBaseline bool           `json:"has_baseline"`
+	Diff        []ConfigChange `json:"diff"`
+	Check       *ConfigCheck   `json:"check,omitempty"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// Path looks like "route.rules[3].outbound"; removed items keep their old index.
+type ConfigChange struct {
+	Path string      `json:"path"`
+	Kind string      `json:"kind"` // added, removed or changed
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+type ConfigCheck struct {
+	OK     bool   `json:"ok"`
+	Output string `json:"output"`
+}
+
+func (a *App) getLastConfigPath() string {
+	return filepath.Join(a.getAppDataDir(), "last_config.json")
+}
+
+func (a *App) configForKey(key string) (string, error) {
+	for _, p := range a.Profiles {
+		if p.Key == key && p.Outbound != nil {
+			return a.generateSelectorConfig(p)
+		}
+	}
+
+	ob, _, err := parseProfileKey(key)
+	if err != nil {
+		return "", err
+	}
+	return a.generateConfig(ob)
+}
+
+func (a *App) PreviewConfig(profileID string) ConfigPreview {
+	var configJSON string
+	var err error
+	if settings := a.settingsSnapshot(); settings.ConnectMode == "failover" {
+		configJSON, err = a.generateFailoverConfig(settings.Failover)
+	} else {
+		var key string
+		for _, p := range a.Profiles {
+			if p.ID == profileID {
+				key = p.Key
+				break
+			}
+		}
+		if key == "" {
+			return ConfigPreview{Error: "Profile not found"}
+		}
+		configJSON, err = a.configForKey(key)
+	}
+	if err != nil {
+		return ConfigPreview{Error: "Config error: " + err.Error()}
+	}
+
+	preview := ConfigPreview{Config: configJSON, Diff: []ConfigChange{}}
+
+	if last, err := os.ReadFile(a.getLastConfigPath()); err == nil {
+		diff, err := diffConfigs(last, []byte(configJSON))
+		if err != nil {
+			preview.Error = "Diff failed: " + err.Error()
+		} else {
+			preview.HasBaseline = true
+			preview.Diff = diff
+		}
+	}
+
+	if binPath, err := a.getProxyBin(); err == nil {
+		preview.Check = a.checkConfig(binPath, configJSON)
+	}
+	return preview
+}
+
+func (a *App) checkConfig(binPath, configJSON string) *ConfigCheck {
+	f, err := os.CreateTemp("", "censaway-check-*.json")
+	if err != nil {
+		return &ConfigCheck{Output: err.Error()}
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(configJSON)
+	f.Close()
+	if err != nil {
+		return &ConfigCheck{Output: err.Error()}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, binPath, "check", "-c", f.Name())
+	cmd.Dir = a.getAppDataDir()
+	a.configureCmd(cmd)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+
+	output := strings.TrimSpace(out.String())
+	if err != nil && output == "" {
+		output = err.Error()
+	}
+	return &ConfigCheck{OK: err == nil, Output: output}
+}
+
+func diffConfigs(oldJSON, newJSON []byte) ([]ConfigChange, error) {
+	oldValue, err := decodeConfigValue(oldJSON)
+	if err != nil {
+		return nil, fmt.Errorf("last config: %w", err)
+	}
+	newValue, err := decodeConfigValue(newJSON)
+	if err != nil {
+		return nil, fmt.Errorf("preview: %w", err)
+	}
+
+	changes := []ConfigChange{}
+	diffValues("", oldValue, newValue, &changes)
+	return changes, nil
+}
+
+func decodeConfigValue(data []byte) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func diffValues(path string, oldValue, newValue interface{}, changes *[]ConfigChange) {
+	switch o := oldValue.(type) {
+	case map[string]interface{}:
+		n, ok := newValue.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(o)+len(n))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		for k := range n {
+			if _, seen := o[k]; !seen {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			ov, inOld := o[k]
+			nv, inNew := n[k]
+			switch {
+			case !inOld:
+				*changes = append(*changes, ConfigChange{Path: child, Kind: "added", New: nv})
+			case !inNew:
+				*changes = append(*changes, ConfigChange{Path: child, Kind: "removed", Old: ov})
+			default:
+				diffValues(child, ov, nv, changes)
+			}
+		}
+		return
+
+	case []interface{}:
+		n, ok := newValue.([]interface{})
+		if !ok {
+			break
+		}
+
+		diffArrays(path, o, n, changes)
+		return
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*changes = append(*changes, ConfigChange{Path: path, Kind: "changed", Old: oldValue, New: newValue})
+	}
+}
+
+// Aligning on the longest common subsequence reports an inserted rule as one
+// addition rather than a change to every rule after it.
+func diffArrays(path string, o, n []interface{}, changes *[]ConfigChange) {
+	lcs := make([][]int, len(o)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(n)+1)
+	}
+	for i := len(o) - 1; i >= 0; i-- {
+		for j := len(n) - 1; j >= 0; j-- {
+			if reflect.DeepEqual(o[i], n[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var removed, added []int
+	flush := func() {
+		for len(removed) > 0 && len(added) > 0 {
+			diffValues(fmt.Sprintf("%s[%d]", path, added[0]), o[removed[0]], n[added[0]], changes)
+			removed, added = removed[1:], added[1:]
+		}
+		for _, i := range removed {
+			*changes = append(*changes, ConfigChange{Path: fmt.Sprintf("%s[%d]", path, i), Kind: "removed", Old: o[i]})
+		}
+		for _, j := range added {
+			*changes = append(*changes, ConfigChange{Path: fmt.Sprintf("%s[%d]", path, j), Kind: "added", New: n[j]})
+		}
+		removed, added = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(o) || j < len(n) {
+		switch {
+		case i < len(o) && j < len(n) && reflect.DeepEqual(o[i], n[j]):
+			flush()
+			i++
+			j++
+		case j >= len(n) || (i < len(o) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, i)
+			i++
+		default:
+			added = append(added, j)
+			j++
+		}
+	}
+	flush()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	cases := []struct {
+		name, old, new string
+		want           []string
+	}{
+		{"equal", `{"a": [1, 2]}`, `{"a": [1, 2]}`, nil},
+		{"scalar", `{"log": {"level": "info"}}`, `{"log": {"level": "debug"}}`, []string{"changed log.level"}},
+		{"keys", `{"a": 1, "b": 2}`, `{"b": 2, "c": 3}`, []string{"removed a", "added c"}},
+		{"inserted rule", `{"rules": [{"x": 1}, {"x": 2}, {"x": 3}]}`, `{"rules": [{"x": 1}, {"x": 9}, {"x": 2}, {"x": 3}]}`, []string{"added rules[1]"}},
+		{"removed rule", `{"rules": [1, 2, 3]}`, `{"rules": [1, 3]}`, []string{"removed rules[1]"}},
+		{"edited item", `{"rules": [{"x": 1}, {"x": 2}]}`, `{"rules": [{"x": 1}, {"x": 5}]}`, []string{"changed rules[1].x"}},
+		{"type change", `{"a": [1]}`, `{"a": {"b": 1}}`, []string{"changed a"}},
+	}
+	for _, c := range cases {
+		changes, err := diffConfigs([]byte(c.old), []byte(c.new))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		got := []string{}
+		for _, ch := range changes {
+			got = append(got, ch.Kind+" "+ch.Path)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(append([]string{}, c.want...)) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	if _, err := diffConfigs([]byte(`{`), []byte(`{}`)); err == nil {
+		t.Error("expected an error for a broken last config")
+	}
+}
+
+func TestPreviewConfigFollowsConnectMode(t *testing.T) {
+	a := ruleSetApp(t)
+	for _, transport := range []string{"tcp", "ws"} {
+		p, err := newProfile(goldenVlessLink(transport, "tls"), transport, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.Profiles = append(a.Profiles, p)
+	}
+	a.Settings.Failover = FailoverGroup{ProfileIDs: []string{a.Profiles[0].ID, a.Profiles[1].ID}}
+
+	preview := a.PreviewConfig(a.Profiles[0].ID)
+	if preview.Error != "" || !strings.Contains(preview.Config, `"type": "selector"`) {
+		t.Errorf("profile mode: %s\n%s", preview.Error, preview.Config)
+	}
+
+	a.Settings.ConnectMode = "failover"
+	want, err := a.generateFailoverConfig(a.Settings.Failover)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview := a.PreviewConfig(a.Profiles[0].ID); preview.Error != "" || preview.Config != want {
+		t.Errorf("failover mode does not preview the urltest config: %s\n%s", preview.Error, preview.Config)
+	}
+}
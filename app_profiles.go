@@ -1,14 +1,13 @@
 package main
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
-	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,6 +24,21 @@ func (a *App) LoadProfiles() []Profile {
 	if a.Profiles == nil {
 		a.Profiles = []Profile{}
 	}
+
+	// Profiles saved by older versions only carry the key.
+	migrated := false
+	for i, p := range a.Profiles {
+		if p.Outbound != nil {
+			continue
+		}
+		if ob, _, err := parseProfileKey(p.Key); err == nil {
+			a.Profiles[i].Outbound = ob
+			migrated = true
+		}
+	}
+	if migrated {
+		a.SaveProfiles()
+	}
 	return a.Profiles
 }
 
@@ -36,20 +50,67 @@ func (a *App) SaveProfiles() error {
 	return os.WriteFile(a.getProfilesPath(), data, 0644)
 }
 
-func (a *App) AddProfile(vlessLink string) string {
-	if !strings.HasPrefix(vlessLink, "vless://") {
-		return "Invalid VLESS link"
-	}
-	u, err := url.Parse(vlessLink)
+func newProfile(key, name, subID string) (Profile, error) {
+	ob, keyName, err := parseProfileKey(key)
 	if err != nil {
-		return "Parse error"
+		return Profile{}, err
 	}
-	name := u.Fragment
 	if name == "" {
-		name = u.Hostname()
+		name = keyName
+	}
+	return Profile{
+		ID:             uuid.New().String(),
+		Name:           name,
+		Key:            key,
+		Outbound:       ob,
+		SubscriptionID: subID,
+		CreatedAt:      time.Now().Unix(),
+	}, nil
+}
+
+func (a *App) profileOutbound(profileID string) *Outbound {
+	for _, p := range a.Profiles {
+		if p.ID != profileID {
+			continue
+		}
+		if p.Outbound == nil {
+			p.Outbound, _, _ = parseProfileKey(p.Key)
+		}
+		return p.Outbound
 	}
-	name, _ = url.QueryUnescape(name)
-	a.Profiles = append(a.Profiles, Profile{ID: uuid.New().String(), Name: name, Key: vlessLink, CreatedAt: time.Now().Unix()})
+	return nil
+}
+
+func (a *App) SetProfileOptions(id string, opts ProfileOptions) string {
+	for i, p := range a.Profiles {
+		if p.ID != id {
+			continue
+		}
+		if p.Outbound != nil {
+			if err := p.Outbound.withOptions(&opts).validate(); err != nil {
+				return err.Error()
+			}
+		}
+		if opts.Multiplex == nil && opts.Fragment == nil {
+			a.Profiles[i].Options = nil
+		} else {
+			a.Profiles[i].Options = &opts
+		}
+		if err := a.SaveProfiles(); err != nil {
+			return "Save failed: " + err.Error()
+		}
+		return "OK"
+	}
+	return "Profile not found"
+}
+
+func (a *App) AddProfile(link string) string {
+	link = normalizeLink(strings.TrimSpace(link))
+	p, err := newProfile(link, "", "")
+	if err != nil {
+		return err.Error()
+	}
+	a.Profiles = append(a.Profiles, p)
 	a.SaveProfiles()
 	return "OK"
 }
@@ -75,51 +136,32 @@ func (a *App) ImportSubscription(subUrl string) string {
 	}
 	defer resp.Body.Close()
 	body, _ := io.ReadAll(resp.Body)
-	decoded, err := base64.StdEncoding.DecodeString(string(body))
-	if err != nil {
-		decoded, _ = base64.URLEncoding.DecodeString(string(body))
-	}
-	content := string(body)
-	if len(decoded) > 0 {
-		content = string(decoded)
-	}
+	links, skipped := parseSubscriptionContent(body)
+	a.logSkipped("Import", skipped)
 	count := 0
-	for _, line := range strings.Split(content, "\n") {
-		if strings.HasPrefix(strings.TrimSpace(line), "vless://") {
-			a.AddProfile(strings.TrimSpace(line))
+	for _, link := range links {
+		if a.AddProfile(link) == "OK" {
 			count++
 		}
 	}
+	if len(skipped) > 0 {
+		return fmt.Sprintf("Imported %d profiles, %d skipped (see logs)", count, len(skipped))
+	}
 	return fmt.Sprintf("Imported %d profiles", count)
 }
 
 func (a *App) TcpPing(profileID string) int {
-	var targetKey string
-	for _, p := range a.Profiles {
-		if p.ID == profileID {
-			targetKey = p.Key
-			break
-		}
-	}
-
-	if targetKey == "" {
+	ob := a.profileOutbound(profileID)
+	if ob == nil {
 		wailsRuntime.EventsEmit(a.ctx, "log", "Ping: Profile not found")
 		return -1
 	}
-
-	u, err := url.Parse(targetKey)
-	if err != nil {
-		wailsRuntime.EventsEmit(a.ctx, "log", "Ping: URL parse error: "+err.Error())
+	if ob.Server == "" || ob.Port == 0 {
+		wailsRuntime.EventsEmit(a.ctx, "log", "Ping: Not supported for this profile type")
 		return -1
 	}
 
-	port := u.Port()
-	if port == "" {
-		port = "443"
-	}
-
-	host := u.Hostname()
-	target := net.JoinHostPort(host, port)
+	target := net.JoinHostPort(ob.Server, strconv.Itoa(ob.Port))
 
 	var conn net.Conn
 	var dialErr error
@@ -143,8 +185,10 @@ func (a *App) TcpPing(profileID string) int {
 }
 
 func (a *App) UpdateProfile(id string, name string, key string) string {
-	if !strings.HasPrefix(key, "vless://") {
-		return "Invalid VLESS key"
+	key = normalizeLink(strings.TrimSpace(key))
+	ob, _, err := parseProfileKey(key)
+	if err != nil {
+		return err.Error()
 	}
 
 	found := false
@@ -152,6 +196,7 @@ func (a *App) UpdateProfile(id string, name string, key string) string {
 		if p.ID == id {
 			a.Profiles[i].Name = name
 			a.Profiles[i].Key = key
+			a.Profiles[i].Outbound = ob
 			found = true
 			break
 		}
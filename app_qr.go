@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	goqrcode "github.com/skip2/go-qrcode"
+)
+
+const qrImageSize = 320
+
+func (a *App) GetProfileQR(profileID string) string {
+	link := a.GetShareLink(profileID)
+	if strings.HasPrefix(link, "Error: ") {
+		return link
+	}
+
+	png, err := goqrcode.Encode(link, goqrcode.Medium, qrImageSize)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+}
+
+func (a *App) ImportQR(source string) string {
+	data, err := readQRSource(strings.TrimSpace(source))
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+
+	text, err := decodeQR(data)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+
+	if isWireGuardConfig(text) {
+		return a.ImportWireGuard(text)
+	}
+
+	links, skipped := parseSubscriptionContent([]byte(text))
+	a.logSkipped("QR", skipped)
+
+	count := 0
+	var lastErr string
+	for _, link := range links {
+		if res := a.AddProfile(link); res == "OK" {
+			count++
+		} else {
+			lastErr = res
+		}
+	}
+	if count == 0 {
+		if lastErr != "" {
+			return "Error: " + lastErr
+		}
+		return "Error: QR code holds no supported link"
+	}
+	return fmt.Sprintf("Imported %d profiles", count)
+}
+
+func readQRSource(source string) ([]byte, error) {
+	if _, err := os.Stat(source); err == nil {
+		return os.ReadFile(source)
+	}
+	if strings.HasPrefix(source, "data:") {
+		idx := strings.Index(source, ",")
+		if idx == -1 {
+			return nil, fmt.Errorf("malformed data URL")
+		}
+		source = source[idx+1:]
+	}
+	data, err := decodeBase64Loose(source)
+	if err != nil {
+		return nil, fmt.Errorf("not an image file or base64 image")
+	}
+	return data, nil
+}
+
+func decodeQR(data []byte) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("unsupported image: %v", err)
+	}
+
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", err
+	}
+
+	hints := map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_TRY_HARDER: true,
+	}
+	result, err := qrcode.NewQRCodeReader().Decode(bmp, hints)
+	if err != nil {
+		return "", fmt.Errorf("no QR code found")
+	}
+	return result.GetText(), nil
+}
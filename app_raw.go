@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Mapped to whether the type dials a server.
+var rawOutboundTypes = map[string]bool{
+	"socks":       true,
+	"http":        true,
+	"shadowsocks": true,
+	"vmess":       true,
+	"trojan":      true,
+	"naive":       true,
+	"hysteria":    true,
+	"shadowtls":   true,
+	"vless":       true,
+	"tuic":        true,
+	"hysteria2":   true,
+	"anytls":      true,
+	"ssh":         true,
+	"tor":         false,
+}
+
+var rawObjectFields = []string{"tls", "transport", "multiplex", "obfs"}
+
+func isRawOutbound(key string) bool {
+	return strings.HasPrefix(key, "{") || strings.HasPrefix(key, "[")
+}
+
+// A bundle is a detour chain, the first outbound dialing through the second;
+// it is how shadowsocks over shadowtls is written.
+func decodeRawOutbounds(raw []byte, useNumber bool) ([]map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if useNumber {
+		dec.UseNumber()
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		var outbounds []map[string]interface{}
+		if err := dec.Decode(&outbounds); err != nil {
+			return nil, err
+		}
+		if len(outbounds) == 0 {
+			return nil, fmt.Errorf("no outbounds")
+		}
+		return outbounds, nil
+	}
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+	return []map[string]interface{}{fields}, nil
+}
+
+// Protocol is that of the first outbound, Server and Port of the last.
+func parseRawOutbound(key string) (*Outbound, string, error) {
+	outbounds, err := decodeRawOutbounds([]byte(key), false)
+	if err != nil {
+		return nil, "", fieldError("sing-box", "key", "bad json: %v", err)
+	}
+
+	var compact bytes.Buffer
+	json.Compact(&compact, []byte(key))
+
+	ob := &Outbound{Raw: json.RawMessage(compact.Bytes())}
+	first, last := outbounds[0], outbounds[len(outbounds)-1]
+	ob.Protocol, _ = first["type"].(string)
+	ob.Server, _ = last["server"].(string)
+	if port, ok := last["server_port"].(float64); ok {
+		ob.Port = int(port)
+	}
+
+	name, _ := first["tag"].(string)
+	if name == "" || name == "proxy" {
+		name = strings.TrimSpace(ob.Protocol + " " + ob.Server)
+	}
+	return ob, name, nil
+}
+
+func (ob *Outbound) validateRaw() error {
+	e := &LinkError{Protocol: "sing-box"}
+
+	outbounds, err := decodeRawOutbounds(ob.Raw, false)
+	if err != nil {
+		e.add("key", "bad json: %v", err)
+		return e
+	}
+
+	tags := map[string]bool{}
+	for i, fields := range outbounds {
+		last := i == len(outbounds)-1
+		validateRawFields(e, fields, last)
+
+		tag, _ := fields["tag"].(string)
+		if i > 0 {
+			if tag == "" {
+				e.add("tag", "outbound %d of the bundle has no tag", i+1)
+			} else if tags[tag] {
+				e.add("tag", "tag %q is used twice", tag)
+			}
+		}
+		tags[tag] = true
+
+		detour, hasDetour := fields["detour"]
+		switch {
+		case !last:
+			next, _ := outbounds[i+1]["tag"].(string)
+			if d, _ := detour.(string); d == "" || d != next {
+				e.add("detour", "outbound %d must detour through the next one (%q)", i+1, next)
+			}
+		case hasDetour && len(outbounds) == 1:
+			e.add("detour", "detour is only supported between the outbounds of a bundle")
+		case hasDetour:
+			e.add("detour", "the last outbound of a bundle can't have a detour")
+		}
+	}
+
+	// shadowtls only carries the handshake, the proxy protocol runs on top.
+	if typ, _ := outbounds[0]["type"].(string); typ == "shadowtls" {
+		e.add("type", "shadowtls needs an outbound on top: paste a JSON array of a shadowsocks outbound with detour set to the shadowtls tag, followed by the shadowtls outbound")
+	}
+
+	return e.errOrNil()
+}
+
+func validateRawFields(e *LinkError, fields map[string]interface{}, last bool) {
+	typ, _ := fields["type"].(string)
+	needsServer, known := rawOutboundTypes[typ]
+	switch {
+	case typ == "":
+		e.add("type", "type missing")
+	case typ == "wireguard":
+		e.add("type", "wireguard is an endpoint, import the .conf instead")
+	case !known:
+		e.add("type", "unsupported outbound type %q", typ)
+	}
+
+	if needsServer && last {
+		if server, ok := fields["server"].(string); !ok || server == "" {
+			e.add("server", "server missing")
+		}
+		port, ok := fields["server_port"].(float64)
+		if !ok {
+			e.add("server_port", "server_port missing")
+		} else if port < 1 || port > 65535 || port != float64(int(port)) {
+			e.add("server_port", "server_port %v out of range", port)
+		}
+	}
+
+	for _, field := range rawObjectFields {
+		if v, ok := fields[field]; ok {
+			if _, isObject := v.(map[string]interface{}); !isObject {
+				e.add(field, "%s must be an object", field)
+			}
+		}
+	}
+
+}
+
+// Bundle members get tag as a prefix so several bundles can share a config.
+func buildRawOutbounds(ob *Outbound, tag, detour string) ([]sbOutbound, error) {
+	outbounds, err := decodeRawOutbounds(ob.Raw, true)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]string, len(outbounds))
+	for i, fields := range outbounds {
+		tags[i] = tag
+		if i > 0 {
+			original, _ := fields["tag"].(string)
+			tags[i] = tag + "-" + original
+		}
+	}
+
+	built := make([]sbOutbound, len(outbounds))
+	for i, fields := range outbounds {
+		typ, _ := fields["type"].(string)
+		o := sbOutbound{Type: typ, raw: fields}
+		o.setTag(tags[i])
+		if i < len(outbounds)-1 {
+			o.setDetour(tags[i+1])
+		} else {
+			o.setDetour(detour)
+		}
+		built[i] = o
+	}
+	return built, nil
+}
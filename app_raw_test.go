@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const shadowTLSBundle = `[
+	{"type": "shadowsocks", "tag": "ss", "method": "2022-blake3-aes-128-gcm", "password": "8JCsPssfgS8tiRwiMlhARg==", "detour": "stls"},
+	{"type": "shadowtls", "tag": "stls", "server": "198.51.100.9", "server_port": 443, "version": 3, "password": "secret", "tls": {"enabled": true, "server_name": "www.example.com"}}
+]`
+
+func TestRawBundle(t *testing.T) {
+	ob, name, err := parseProfileKey(shadowTLSBundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ob.Protocol != "shadowsocks" || ob.Server != "198.51.100.9" || ob.Port != 443 || name != "ss" {
+		t.Errorf("lifted %s %s:%d %q, want the shadowsocks protocol and the shadowtls server", ob.Protocol, ob.Server, ob.Port, name)
+	}
+
+	b := newConfigBuilder(Settings{RunMode: "tun", RoutingMode: "global", MixedPort: 2080})
+	if err := b.addChainedProxy("proxy-1", ob, "proxy-0"); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := json.Marshal(b.config.Outbounds)
+	want := `[{"type":"shadowsocks","tag":"proxy-1","method":"2022-blake3-aes-128-gcm","password":"8JCsPssfgS8tiRwiMlhARg==","detour":"proxy-1-stls"},` +
+		`{"type":"shadowtls","tag":"proxy-1-stls","server":"198.51.100.9","server_port":443,"version":3,"password":"secret","tls":{"enabled":true,"server_name":"www.example.com"},"detour":"proxy-0"}]`
+	if !jsonEqual(t, out, []byte(want)) {
+		t.Errorf("outbounds:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestRawValidation(t *testing.T) {
+	for name, key := range map[string]string{
+		"shadowtls alone": `{"type": "shadowtls", "server": "198.51.100.9", "server_port": 443}`,
+		"detour alone":    `{"type": "socks", "server": "198.51.100.9", "server_port": 1080, "detour": "x"}`,
+		"wrong detour":    `[{"type": "shadowsocks", "method": "aes-128-gcm", "password": "p", "detour": "other"}, {"type": "shadowtls", "tag": "stls", "server": "198.51.100.9", "server_port": 443}]`,
+		"untagged":        `[{"type": "shadowsocks", "method": "aes-128-gcm", "password": "p", "detour": ""}, {"type": "shadowtls", "server": "198.51.100.9", "server_port": 443}]`,
+		"last detours":    `[{"type": "shadowsocks", "detour": "stls"}, {"type": "shadowtls", "tag": "stls", "server": "198.51.100.9", "server_port": 443, "detour": "x"}]`,
+		"no server":       `[{"type": "shadowsocks", "detour": "stls"}, {"type": "shadowtls", "tag": "stls"}]`,
+		"empty bundle":    `[]`,
+		"not json":        `{"type": `,
+	} {
+		if _, _, err := parseProfileKey(key); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatal(err)
+	}
+	xs, _ := json.Marshal(x)
+	ys, _ := json.Marshal(y)
+	return string(xs) == string(ys)
+}
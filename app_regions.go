@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+//go:generate curl -fsSLo rulesets/geoip-ru.srs https://raw.githubusercontent.com/SagerNet/sing-geoip/rule-set/geoip-ru.srs
+//go:generate curl -fsSLo rulesets/geoip-kz.srs https://raw.githubusercontent.com/SagerNet/sing-geoip/rule-set/geoip-kz.srs
+//go:generate curl -fsSLo rulesets/geoip-by.srs https://raw.githubusercontent.com/SagerNet/sing-geoip/rule-set/geoip-by.srs
+//go:generate curl -fsSLo rulesets/geoip-ir.srs https://raw.githubusercontent.com/SagerNet/sing-geoip/rule-set/geoip-ir.srs
+//go:generate curl -fsSLo rulesets/geoip-cn.srs https://raw.githubusercontent.com/SagerNet/sing-geoip/rule-set/geoip-cn.srs
+
+// Region domains use the system resolver, or DNS with Settings.RegionDNS.
+type Region struct {
+	Code    string   `json:"code"`
+	Name    string   `json:"name"`
+	Domains []string `json:"domains"`
+	// Empty when there is no well-known resolver inside the country.
+	DNS string `json:"dns,omitempty"`
+}
+
+var regions = []Region{
+	{Code: "ru", Name: "Russia", Domains: []string{".ru", ".rf", ".xn--p1ai"}, DNS: "77.88.8.8"},
+	{Code: "kz", Name: "Kazakhstan", Domains: []string{".kz", ".xn--80ao21a"}},
+	{Code: "by", Name: "Belarus", Domains: []string{".by", ".xn--90ais"}},
+	{Code: "ir", Name: "Iran", Domains: []string{".ir", ".xn--mgba3a4f16a"}, DNS: "178.22.122.100"},
+	{Code: "cn", Name: "China", Domains: []string{".cn", ".xn--fiqs8s", ".xn--fiqz9s"}, DNS: "223.5.5.5"},
+}
+
+func findRegion(code string) (Region, bool) {
+	for _, r := range regions {
+		if r.Code == code {
+			return r, true
+		}
+	}
+	return Region{}, false
+}
+
+func (r Region) ruleSetTag() string { return "geoip-" + r.Code }
+
+func (r Region) ruleSetURL() string {
+	return "https://raw.githubusercontent.com/SagerNet/sing-geoip/rule-set/geoip-" + r.Code + ".srs"
+}
+
+func (r Region) dnsTag() string { return r.Code + "_dns" }
+
+func isRegionTag(tag string) bool {
+	for _, r := range regions {
+		if tag == r.ruleSetTag() || tag == r.dnsTag() {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeRegionCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// Unknown codes are skipped and the first one is reported in the error.
+func selectedRegions(codes []string) ([]Region, error) {
+	selected := []Region{}
+	seen := map[string]bool{}
+	var err error
+	for _, code := range codes {
+		code = normalizeRegionCode(code)
+		r, ok := findRegion(code)
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("unknown region %q", code)
+			}
+			continue
+		}
+		if !seen[code] {
+			seen[code] = true
+			selected = append(selected, r)
+		}
+	}
+	return selected, err
+}
+
+func (b *configBuilder) smartRegions() ([]Region, error) {
+	if b.settings.RoutingMode != "smart" {
+		return nil, nil
+	}
+	selected, err := selectedRegions(b.settings.Regions)
+	if err != nil {
+		return nil, err
+	}
+	return selected, nil
+}
+
+// migrateRuDomains converts settings saved before regions existed.
+func migrateRuDomains(s *Settings) {
+	if len(s.RuDomains) == 0 {
+		return
+	}
+	ru, _ := findRegion("ru")
+	preset := map[string]bool{}
+	for _, d := range ru.Domains {
+		preset[d] = true
+	}
+	have := map[string]bool{}
+	for _, d := range s.DirectDomains {
+		have[d] = true
+	}
+	for _, d := range s.RuDomains {
+		if !preset[d] && !have[d] {
+			s.DirectDomains = append(s.DirectDomains, d)
+			have[d] = true
+		}
+	}
+
+	hasRu := false
+	for _, code := range s.Regions {
+		hasRu = hasRu || normalizeRegionCode(code) == "ru"
+	}
+	if !hasRu {
+		s.Regions = append(s.Regions, "ru")
+	}
+	s.RuDomains = nil
+}
+
+func (a *App) GetRegions() []Region { return regions }
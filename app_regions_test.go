@@ -0,0 +1,91 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func regionCodes(rs []Region) []string {
+	codes := []string{}
+	for _, r := range rs {
+		codes = append(codes, r.Code)
+	}
+	return codes
+}
+
+func TestSmartRegions(t *testing.T) {
+	b := newConfigBuilder(Settings{RoutingMode: "smart", Regions: []string{" KZ", "cn", "kz ", "Cn"}})
+	rs, err := b.smartRegions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := regionCodes(rs); !reflect.DeepEqual(got, []string{"kz", "cn"}) {
+		t.Errorf("regions %v, want [kz cn]", got)
+	}
+
+	b.settings.Regions = []string{"ru", "XX"}
+	if _, err := b.smartRegions(); err == nil || !strings.Contains(err.Error(), `"xx"`) {
+		t.Errorf("unknown region: %v", err)
+	}
+
+	b.settings.RoutingMode = "global"
+	if rs, err := b.smartRegions(); rs != nil || err != nil {
+		t.Errorf("global mode: %v, %v", rs, err)
+	}
+}
+
+func TestRegionDNS(t *testing.T) {
+	rules := func(s Settings) map[string]string {
+		b := newConfigBuilder(s)
+		if err := b.addDNS(); err != nil {
+			t.Fatal(err)
+		}
+		servers := map[string]string{}
+		for _, r := range b.config.DNS.Rules {
+			for _, d := range r.DomainSuffix {
+				servers[d] = r.Server
+			}
+		}
+		return servers
+	}
+
+	s := Settings{RoutingMode: "smart", Regions: []string{"ru", "kz"}, DirectDomains: []string{"corp.example.com"}}
+	for d, server := range rules(s) {
+		if server != "local_dns" {
+			t.Errorf("%s resolved by %s without region DNS", d, server)
+		}
+	}
+
+	// kz has no domestic resolver and keeps the system one.
+	s.RegionDNS = true
+	got := rules(s)
+	if got[".ru"] != "ru_dns" || got[".kz"] != "local_dns" || got["corp.example.com"] != "local_dns" {
+		t.Errorf("region DNS rules %v", got)
+	}
+}
+
+func TestMigrateRuDomains(t *testing.T) {
+	for name, c := range map[string]struct {
+		before, after Settings
+	}{
+		"user domains": {
+			Settings{Regions: []string{"kz"}, RuDomains: []string{".ru", ".rf", ".xn--p1ai", "yandex.net", "vk.com"}},
+			Settings{Regions: []string{"kz", "ru"}, DirectDomains: []string{"yandex.net", "vk.com"}},
+		},
+		"ru present": {
+			Settings{Regions: []string{" RU"}, DirectDomains: []string{"vk.com"}, RuDomains: []string{".ru", "vk.com", "mail.ru"}},
+			Settings{Regions: []string{" RU"}, DirectDomains: []string{"vk.com", "mail.ru"}},
+		},
+		"already migrated": {
+			Settings{Regions: []string{}, DirectDomains: []string{"vk.com"}},
+			Settings{Regions: []string{}, DirectDomains: []string{"vk.com"}},
+		},
+	} {
+		s := c.before
+		migrateRuDomains(&s)
+		if !reflect.DeepEqual(s, c.after) {
+			t.Errorf("%s: got regions %v, direct domains %v, ru domains %v", name, s.Regions, s.DirectDomains, s.RuDomains)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type RuleCondition struct {
+	Type   string   `json:"type"`
+	Values []string `json:"values"`
+	Invert bool     `json:"invert,omitempty"`
+}
+
+// Format is guessed from the file extension when empty.
+type RuleSet struct {
+	Tag    string `json:"tag"`
+	Type   string `json:"type"`
+	Format string `json:"format,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+// Tags name the cached file, so they must not contain path separators.
+var ruleSetTagPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+var sniffedProtocols = map[string]bool{
+	"http": true, "tls": true, "quic": true, "stun": true, "dns": true,
+	"bittorrent": true, "dtls": true, "ssh": true, "rdp": true, "ntp": true,
+}
+
+func (r UserRule) conditions() []RuleCondition {
+	if len(r.Conditions) > 0 {
+		return r.Conditions
+	}
+	values := r.Values
+	if r.Value != "" {
+		values = append([]string{r.Value}, values...)
+	}
+	return []RuleCondition{{Type: r.Type, Values: values}}
+}
+
+func (r UserRule) build(ruleSets map[string]bool) (sbRule, error) {
+	conditions := r.conditions()
+	rules := make([]sbRule, 0, len(conditions))
+	for _, c := range conditions {
+		rule, err := c.build(ruleSets)
+		if err != nil {
+			return sbRule{}, err
+		}
+		rules = append(rules, rule)
+	}
+
+	rule := rules[0]
+	if len(rules) > 1 {
+		mode := r.Mode
+		if mode == "" {
+			mode = "and"
+		}
+		if mode != "and" && mode != "or" {
+			return sbRule{}, fmt.Errorf("unknown mode %q", r.Mode)
+		}
+		rule = sbRule{Type: "logical", Mode: mode, Rules: rules}
+	}
+
+	switch r.Outbound {
+	case "block":
+		rule.Action = "reject"
+	case "direct", "proxy":
+		rule.Action = "route"
+		rule.Outbound = r.Outbound
+	default:
+		return sbRule{}, fmt.Errorf("unknown outbound %q", r.Outbound)
+	}
+	return rule, nil
+}
+
+func (c RuleCondition) build(ruleSets map[string]bool) (sbRule, error) {
+	values := []string{}
+	for _, v := range c.Values {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return sbRule{}, fmt.Errorf("%s: no values", c.Type)
+	}
+
+	r := sbRule{Invert: c.Invert}
+	switch c.Type {
+	case "domain":
+		r.DomainSuffix = values
+	case "domain_full":
+		r.Domain = values
+	case "domain_keyword":
+		r.DomainKeyword = values
+	case "domain_regex":
+		for _, v := range values {
+			if _, err := regexp.Compile(v); err != nil {
+				return sbRule{}, fmt.Errorf("domain_regex %q: %w", v, err)
+			}
+		}
+		r.DomainRegex = values
+	case "ip", "source_ip":
+		for _, v := range values {
+			if _, _, err := net.ParseCIDR(v); err != nil && net.ParseIP(v) == nil {
+				return sbRule{}, fmt.Errorf("%s %q is not an IP or CIDR", c.Type, v)
+			}
+		}
+		if c.Type == "ip" {
+			r.IPCIDR = values
+		} else {
+			r.SourceIPCIDR = values
+		}
+	case "port":
+		for _, v := range values {
+			from, to, err := parsePortRange(v)
+			if err != nil {
+				return sbRule{}, err
+			}
+			if from == to {
+				r.Port = append(r.Port, from)
+			} else {
+				r.PortRange = append(r.PortRange, fmt.Sprintf("%d:%d", from, to))
+			}
+		}
+	case "network":
+		for _, v := range values {
+			if v != "tcp" && v != "udp" {
+				return sbRule{}, fmt.Errorf("network must be tcp or udp, not %q", v)
+			}
+		}
+		r.Network = values
+	case "protocol":
+		for _, v := range values {
+			if !sniffedProtocols[v] {
+				return sbRule{}, fmt.Errorf("unknown protocol %q", v)
+			}
+		}
+		r.Protocol = values
+	case "process":
+		r.ProcessName = values
+	case "rule_set":
+		for _, v := range values {
+			if !ruleSets[v] {
+				return sbRule{}, fmt.Errorf("rule-set %q not found", v)
+			}
+		}
+		r.RuleSet = values
+	default:
+		return sbRule{}, fmt.Errorf("unknown rule type %q", c.Type)
+	}
+	return r, nil
+}
+
+// parsePortRange accepts "443", "1000-2000" and "1000:2000".
+func parsePortRange(v string) (int, int, error) {
+	parts := strings.FieldsFunc(v, func(r rune) bool { return r == '-' || r == ':' })
+	if len(parts) < 1 || len(parts) > 2 {
+		return 0, 0, fmt.Errorf("invalid port %q", v)
+	}
+	ports := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 || n > 65535 {
+			return 0, 0, fmt.Errorf("invalid port %q", v)
+		}
+		ports[i] = n
+	}
+	from, to := ports[0], ports[len(ports)-1]
+	if from > to {
+		return 0, 0, fmt.Errorf("invalid port range %q", v)
+	}
+	return from, to, nil
+}
+
+func (rs RuleSet) build() (sbRuleSet, error) {
+	if rs.Tag == "" {
+		return sbRuleSet{}, fmt.Errorf("rule-set has no tag")
+	}
+	if !ruleSetTagPattern.MatchString(rs.Tag) {
+		return sbRuleSet{}, fmt.Errorf("rule-set tag %q may only contain letters, digits, - and _", rs.Tag)
+	}
+	out := sbRuleSet{Tag: rs.Tag, Type: rs.Type, Format: rs.Format}
+	location := ""
+	switch rs.Type {
+	case "remote":
+		if !strings.HasPrefix(rs.URL, "http://") && !strings.HasPrefix(rs.URL, "https://") {
+			return sbRuleSet{}, fmt.Errorf("rule-set %s: url must be http or https", rs.Tag)
+		}
+		out.URL, location = rs.URL, rs.URL
+		out.DownloadDetour = "proxy"
+	case "local":
+		if rs.Path == "" {
+			return sbRuleSet{}, fmt.Errorf("rule-set %s: no path", rs.Tag)
+		}
+		out.Path, location = rs.Path, rs.Path
+	default:
+		return sbRuleSet{}, fmt.Errorf("rule-set %s: unknown type %q", rs.Tag, rs.Type)
+	}
+
+	if out.Format == "" {
+		out.Format = "binary"
+		if strings.HasSuffix(strings.ToLower(location), ".json") {
+			out.Format = "source"
+		}
+	}
+	if out.Format != "binary" && out.Format != "source" {
+		return sbRuleSet{}, fmt.Errorf("rule-set %s: unknown format %q", rs.Tag, rs.Format)
+	}
+	return out, nil
+}
+
+func userRuleSets(sets []RuleSet) (map[string]sbRuleSet, error) {
+	built := map[string]sbRuleSet{}
+	for _, rs := range sets {
+		out, err := rs.build()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := built[rs.Tag]; ok || isRegionTag(rs.Tag) {
+			return nil, fmt.Errorf("rule-set tag %q is used twice or reserved", rs.Tag)
+		}
+		built[rs.Tag] = out
+	}
+	return built, nil
+}
+
+func (b *configBuilder) addUserRules() error {
+	sets, err := userRuleSets(b.settings.RuleSets)
+	if err != nil {
+		return err
+	}
+	tags := map[string]bool{}
+	for tag := range sets {
+		tags[tag] = true
+	}
+
+	used := map[string]bool{}
+	for i, ur := range b.settings.UserRules {
+		rule, err := ur.build(tags)
+		if err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		b.addRule(rule)
+		for _, c := range ur.conditions() {
+			if c.Type == "rule_set" {
+				for _, tag := range c.Values {
+					used[strings.TrimSpace(tag)] = true
+				}
+			}
+		}
+	}
+
+	for _, rs := range b.settings.RuleSets {
+		if used[rs.Tag] {
+			b.config.Route.RuleSet = append(b.config.Route.RuleSet, sets[rs.Tag])
+		}
+	}
+	return nil
+}
+
+func (a *App) ValidateUserRule(rule UserRule) string {
+	sets, err := userRuleSets(a.settingsSnapshot().RuleSets)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	tags := map[string]bool{}
+	for tag := range sets {
+		tags[tag] = true
+	}
+	if _, err := rule.build(tags); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "OK"
+}
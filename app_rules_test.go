@@ -0,0 +1,155 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePortRange(t *testing.T) {
+	for v, want := range map[string][2]int{
+		"443":       {443, 443},
+		"1000-2000": {1000, 2000},
+		"1000:2000": {1000, 2000},
+		"1-65535":   {1, 65535},
+		"80-80":     {80, 80},
+	} {
+		from, to, err := parsePortRange(v)
+		if err != nil || from != want[0] || to != want[1] {
+			t.Errorf("%q: %d %d %v, want %v", v, from, to, err, want)
+		}
+	}
+	for _, v := range []string{"", "0", "70000", "http", "2000-1000", "1-2-3", "-"} {
+		if _, _, err := parsePortRange(v); err == nil {
+			t.Errorf("%q: expected an error", v)
+		}
+	}
+}
+
+func TestRuleConditionBuild(t *testing.T) {
+	sets := map[string]bool{"ads": true}
+	c := RuleCondition{Type: "port", Values: []string{" 22 ", "", "6881-6889"}, Invert: true}
+	r, err := c.build(sets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Port, []int{22}) || !reflect.DeepEqual(r.PortRange, []string{"6881:6889"}) || !r.Invert {
+		t.Errorf("port rule %+v", r)
+	}
+
+	for name, c := range map[string]RuleCondition{
+		"bad regex":    {Type: "domain_regex", Values: []string{"(unclosed"}},
+		"bad cidr":     {Type: "ip", Values: []string{"10.0.0.0/33"}},
+		"bad source":   {Type: "source_ip", Values: []string{"lan"}},
+		"bad port":     {Type: "port", Values: []string{"70000"}},
+		"bad range":    {Type: "port", Values: []string{"2000-1000"}},
+		"bad network":  {Type: "network", Values: []string{"sctp"}},
+		"bad protocol": {Type: "protocol", Values: []string{"gopher"}},
+		"no rule-set":  {Type: "rule_set", Values: []string{"missing"}},
+		"no values":    {Type: "domain", Values: []string{" ", ""}},
+		"bad type":     {Type: "geosite", Values: []string{"google"}},
+	} {
+		if _, err := c.build(sets); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestUserRuleBuild(t *testing.T) {
+	network := RuleCondition{Type: "network", Values: []string{"udp"}}
+	port := RuleCondition{Type: "port", Values: []string{"443"}}
+
+	simple, err := UserRule{Type: "domain", Value: "example.com", Values: []string{"example.org"}, Outbound: "block"}.build(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if simple.Action != "reject" || !reflect.DeepEqual(simple.DomainSuffix, []string{"example.com", "example.org"}) {
+		t.Errorf("simple rule %+v", simple)
+	}
+
+	for _, mode := range []string{"", "and", "or"} {
+		r, err := UserRule{Mode: mode, Outbound: "direct", Conditions: []RuleCondition{network, port}}.build(nil)
+		if err != nil {
+			t.Fatalf("mode %q: %v", mode, err)
+		}
+		want := mode
+		if want == "" {
+			want = "and"
+		}
+		if r.Type != "logical" || r.Mode != want || len(r.Rules) != 2 || r.Action != "route" || r.Outbound != "direct" {
+			t.Errorf("mode %q: %+v", mode, r)
+		}
+	}
+
+	// A single condition needs no logical wrapper, whatever the mode.
+	r, err := UserRule{Mode: "or", Outbound: "proxy", Conditions: []RuleCondition{port}}.build(nil)
+	if err != nil || r.Type != "" || !reflect.DeepEqual(r.Port, []int{443}) {
+		t.Errorf("single condition: %+v %v", r, err)
+	}
+
+	for name, rule := range map[string]UserRule{
+		"bad mode":      {Mode: "xor", Outbound: "direct", Conditions: []RuleCondition{network, port}},
+		"bad outbound":  {Type: "domain", Values: []string{"example.com"}, Outbound: "tor"},
+		"bad condition": {Outbound: "direct", Conditions: []RuleCondition{network, {Type: "port", Values: []string{"0"}}}},
+	} {
+		if _, err := rule.build(nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRuleSetBuild(t *testing.T) {
+	for _, c := range []struct {
+		rs     RuleSet
+		format string
+	}{
+		{RuleSet{Tag: "a", Type: "remote", URL: "https://example.com/a.srs"}, "binary"},
+		{RuleSet{Tag: "b", Type: "remote", URL: "https://example.com/b.JSON"}, "source"},
+		{RuleSet{Tag: "c", Type: "local", Path: "/etc/c.json"}, "source"},
+		{RuleSet{Tag: "d", Type: "local", Path: "/etc/d.json", Format: "binary"}, "binary"},
+	} {
+		out, err := c.rs.build()
+		if err != nil {
+			t.Fatalf("%s: %v", c.rs.Tag, err)
+		}
+		if out.Format != c.format {
+			t.Errorf("%s: format %q, want %q", c.rs.Tag, out.Format, c.format)
+		}
+	}
+
+	out, _ := RuleSet{Tag: "a", Type: "remote", URL: "https://example.com/a.srs"}.build()
+	if out.DownloadDetour != "proxy" {
+		t.Errorf("remote rule-set detour %q, want proxy", out.DownloadDetour)
+	}
+
+	for name, rs := range map[string]RuleSet{
+		"no tag":   {Type: "remote", URL: "https://example.com/a.srs"},
+		"path tag": {Tag: "../../x", Type: "remote", URL: "https://example.com/a.srs"},
+		"dot tag":  {Tag: "ads.srs", Type: "remote", URL: "https://example.com/a.srs"},
+		"bad url":  {Tag: "a", Type: "remote", URL: "ftp://example.com/a.srs"},
+		"no path":  {Tag: "a", Type: "local"},
+		"bad type": {Tag: "a", Type: "inline"},
+		"format":   {Tag: "a", Type: "local", Path: "/etc/a.srs", Format: "yaml"},
+	} {
+		if _, err := rs.build(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestUserRuleSets(t *testing.T) {
+	a := RuleSet{Tag: "ads", Type: "remote", URL: "https://example.com/ads.srs"}
+	sets, err := userRuleSets([]RuleSet{a, {Tag: "corp", Type: "local", Path: "/etc/corp.json"}})
+	if err != nil || len(sets) != 2 {
+		t.Fatalf("sets %v, err %v", sets, err)
+	}
+
+	for name, list := range map[string][]RuleSet{
+		"duplicate": {a, a},
+		"region":    {{Tag: "geoip-ru", Type: "remote", URL: "https://example.com/ru.srs"}},
+	} {
+		if _, err := userRuleSets(list); err == nil || !strings.Contains(err.Error(), "used twice or reserved") {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
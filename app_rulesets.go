@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// embeddedRuleSets holds fallback copies named <tag>.srs, used until a
+// download succeeds.
+//
+//go:embed rulesets
+var embeddedRuleSets embed.FS
+
+const (
+	ruleSetMaxAge = 24 * time.Hour
+	// srsMaxVersion is the newest format the bundled sing-box reads.
+	srsMaxVersion = 3
+	srsMaxSize    = 32 << 20
+)
+
+type cachedRuleSet struct {
+	URL          string `json:"url"`
+	SHA256       string `json:"sha256"`
+	Version      int    `json:"version"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	UpdatedAt    int64  `json:"updated_at"`
+	Embedded     bool   `json:"embedded,omitempty"`
+}
+
+type RuleSetStatus struct {
+	Tag       string `json:"tag"`
+	URL       string `json:"url"`
+	Cached    bool   `json:"cached"`
+	Embedded  bool   `json:"embedded"`
+	Version   int    `json:"version"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// Sources include regions of every mode so switching modes works offline.
+func ruleSetSources(s Settings) []RuleSet {
+	sources := []RuleSet{}
+	known, _ := selectedRegions(s.Regions)
+	for _, r := range known {
+		sources = append(sources, RuleSet{Tag: r.ruleSetTag(), Type: "remote", Format: "binary", URL: r.ruleSetURL()})
+	}
+	for _, rs := range s.RuleSets {
+		if built, err := rs.build(); err == nil && built.Type == "remote" && built.Format == "binary" {
+			sources = append(sources, rs)
+		}
+	}
+	return sources
+}
+
+func (a *App) getRuleSetManifestPath() string {
+	return filepath.Join(a.getRuleSetDir(), "manifest.json")
+}
+
+func (a *App) loadRuleSetManifest() map[string]cachedRuleSet {
+	manifest := map[string]cachedRuleSet{}
+	if data, err := os.ReadFile(a.getRuleSetManifestPath()); err == nil {
+		json.Unmarshal(data, &manifest)
+	}
+	return manifest
+}
+
+func (a *App) saveRuleSetManifest(manifest map[string]cachedRuleSet) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(a.getRuleSetManifestPath(), data, 0644)
+}
+
+func (a *App) cachedRuleSetFiles() map[string]string {
+	a.ruleSetLock.Lock()
+	defer a.ruleSetLock.Unlock()
+
+	files := map[string]string{}
+	for tag, entry := range a.loadRuleSetManifest() {
+		path := a.getSrsPath(tag)
+		if _, err := os.Stat(path); err == nil {
+			files[entry.URL] = path
+		}
+	}
+	return files
+}
+
+func (a *App) builder() *configBuilder {
+	b := newConfigBuilder(a.settingsSnapshot())
+	b.ruleSetFiles = a.cachedRuleSetFiles()
+	return b
+}
+
+func checkSrs(data []byte) (int, error) {
+	if len(data) < 4 || string(data[:3]) != "SRS" {
+		return 0, fmt.Errorf("not a binary rule-set")
+	}
+	version := int(data[3])
+	if version < 1 || version > srsMaxVersion {
+		return 0, fmt.Errorf("unsupported rule-set version %d", version)
+	}
+	r, err := zlib.NewReader(bytes.NewReader(data[4:]))
+	if err != nil {
+		return 0, fmt.Errorf("corrupt rule-set: %w", err)
+	}
+	defer r.Close()
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return 0, fmt.Errorf("corrupt rule-set: %w", err)
+	}
+	return version, nil
+}
+
+func (a *App) writeRuleSet(tag string, data []byte) error {
+	if err := os.MkdirAll(a.getRuleSetDir(), 0755); err != nil {
+		return err
+	}
+	tmp := a.getSrsPath(tag) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, a.getSrsPath(tag))
+}
+
+func (a *App) ensureEmbeddedRuleSets() {
+	a.ruleSetLock.Lock()
+	defer a.ruleSetLock.Unlock()
+
+	manifest := a.loadRuleSetManifest()
+	changed := false
+	for _, src := range ruleSetSources(a.settingsSnapshot()) {
+		if _, err := os.Stat(a.getSrsPath(src.Tag)); err == nil {
+			continue
+		}
+		data, err := embeddedRuleSets.ReadFile("rulesets/" + src.Tag + ".srs")
+		if err != nil {
+			continue
+		}
+		version, err := checkSrs(data)
+		if err != nil {
+			a.log("Embedded rule-set " + src.Tag + ": " + err.Error())
+			continue
+		}
+		if err := a.writeRuleSet(src.Tag, data); err != nil {
+			a.log("Embedded rule-set " + src.Tag + ": " + err.Error())
+			continue
+		}
+		sum := sha256.Sum256(data)
+		manifest[src.Tag] = cachedRuleSet{URL: src.URL, SHA256: hex.EncodeToString(sum[:]), Version: version, Embedded: true}
+		changed = true
+	}
+	if changed {
+		a.saveRuleSetManifest(manifest)
+	}
+}
+
+// A nil body means the cached copy is still current.
+func (a *App) fetchRuleSet(rawURL string, cached cachedRuleSet) ([]byte, *http.Response, error) {
+	clients := []*http.Client{{Timeout: 30 * time.Second}}
+	if a.GetRunningState() {
+		proxy, _ := url.Parse("http://127.0.0.1:" + strconv.Itoa(a.settingsSnapshot().MixedPort))
+		clients = append(clients, &http.Client{
+			Timeout:   60 * time.Second,
+			Transport: &http.Transport{Proxy: http.ProxyURL(proxy)},
+		})
+	}
+
+	var lastErr error
+	for _, client := range clients {
+		req, err := http.NewRequest("GET", rawURL, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, srsMaxSize+1))
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusNotModified:
+			return nil, resp, nil
+		case resp.StatusCode != http.StatusOK:
+			lastErr = fmt.Errorf("status %d", resp.StatusCode)
+		case err != nil:
+			lastErr = err
+		case len(body) > srsMaxSize:
+			lastErr = fmt.Errorf("rule-set larger than %d MB", srsMaxSize>>20)
+		default:
+			return body, resp, nil
+		}
+	}
+	return nil, nil, lastErr
+}
+
+// Downloads run without ruleSetLock so config generation does not wait.
+func (a *App) refreshRuleSets(force bool) (int, error) {
+	a.ruleSetLock.Lock()
+	manifest := a.loadRuleSetManifest()
+	a.ruleSetLock.Unlock()
+
+	updated := 0
+	var firstErr error
+	for _, src := range ruleSetSources(a.settingsSnapshot()) {
+		entry, ok := manifest[src.Tag]
+		_, statErr := os.Stat(a.getSrsPath(src.Tag))
+		current := ok && statErr == nil && entry.URL == src.URL && !entry.Embedded
+		if current && !force && time.Since(time.Unix(entry.UpdatedAt, 0)) < ruleSetMaxAge {
+			continue
+		}
+		if !current {
+			entry = cachedRuleSet{URL: src.URL}
+		}
+
+		data, resp, err := a.fetchRuleSet(src.URL, entry)
+		if err == nil {
+			err = a.storeRuleSet(src.Tag, entry, data, resp)
+		}
+		if err != nil {
+			a.log(fmt.Sprintf("Rule-set %s: %v", src.Tag, err))
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", src.Tag, err)
+			}
+			continue
+		}
+		if data != nil {
+			updated++
+		}
+	}
+	return updated, firstErr
+}
+
+func (a *App) storeRuleSet(tag string, entry cachedRuleSet, data []byte, resp *http.Response) error {
+	if data != nil {
+		version, err := checkSrs(data)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		entry = cachedRuleSet{URL: entry.URL, SHA256: hex.EncodeToString(sum[:]), Version: version}
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		entry.ETag = etag
+	}
+	if modified := resp.Header.Get("Last-Modified"); modified != "" {
+		entry.LastModified = modified
+	}
+	entry.UpdatedAt = time.Now().Unix()
+
+	a.ruleSetLock.Lock()
+	defer a.ruleSetLock.Unlock()
+	if data != nil {
+		if err := a.writeRuleSet(tag, data); err != nil {
+			return err
+		}
+	}
+	manifest := a.loadRuleSetManifest()
+	manifest[tag] = entry
+	return a.saveRuleSetManifest(manifest)
+}
+
+func (a *App) startRuleSetUpdater() {
+	a.ensureEmbeddedRuleSets()
+	go func() {
+		for {
+			if n, _ := a.refreshRuleSets(false); n > 0 {
+				a.log(fmt.Sprintf("Updated %d rule-set(s)", n))
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
+}
+
+func (a *App) UpdateRuleSets() string {
+	n, err := a.refreshRuleSets(true)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	return fmt.Sprintf("Updated: %d rule-sets", n)
+}
+
+func (a *App) GetRuleSetStatus() []RuleSetStatus {
+	a.ruleSetLock.Lock()
+	manifest := a.loadRuleSetManifest()
+	a.ruleSetLock.Unlock()
+
+	status := []RuleSetStatus{}
+	for _, src := range ruleSetSources(a.settingsSnapshot()) {
+		entry, ok := manifest[src.Tag]
+		_, err := os.Stat(a.getSrsPath(src.Tag))
+		cached := ok && err == nil && entry.URL == src.URL
+		s := RuleSetStatus{Tag: src.Tag, URL: src.URL, Cached: cached}
+		if cached {
+			s.Embedded = entry.Embedded
+			s.Version = entry.Version
+			s.UpdatedAt = entry.UpdatedAt
+		}
+		status = append(status, s)
+	}
+	return status
+}
@@ -0,0 +1,20 @@
+//go:build release
+
+package main
+
+import "testing"
+
+// TestEmbeddedRuleSets runs in release builds, after go generate fetched the
+// rule-sets, so no build ships without its offline fallbacks.
+func TestEmbeddedRuleSets(t *testing.T) {
+	for _, r := range regions {
+		data, err := embeddedRuleSets.ReadFile("rulesets/" + r.ruleSetTag() + ".srs")
+		if err != nil {
+			t.Errorf("%s: %v", r.Code, err)
+			continue
+		}
+		if _, err := checkSrs(data); err != nil {
+			t.Errorf("%s: %v", r.Code, err)
+		}
+	}
+}
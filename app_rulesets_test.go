@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// testSrs returns a minimal binary rule-set of the given format version.
+func testSrs(version byte, payload string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("SRS")
+	buf.WriteByte(version)
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte(payload))
+	w.Close()
+	return buf.Bytes()
+}
+
+// ruleSetApp returns an app whose data directory is a temporary one.
+func ruleSetApp(t *testing.T) *App {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("APPDATA", dir)
+	t.Setenv("HOME", dir)
+	a := NewApp()
+	a.Settings.Regions = nil
+	return a
+}
+
+func TestCheckSrs(t *testing.T) {
+	if v, err := checkSrs(testSrs(2, "rules")); err != nil || v != 2 {
+		t.Errorf("valid rule-set: version %d, err %v", v, err)
+	}
+	truncated := testSrs(1, "rules")
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"not srs":   []byte("{\"version\": 1}"),
+		"version 0": testSrs(0, "rules"),
+		"too new":   testSrs(srsMaxVersion+1, "rules"),
+		"truncated": truncated[:len(truncated)-3],
+	} {
+		if _, err := checkSrs(data); err == nil {
+			t.Errorf("%s: expected checkSrs to fail", name)
+		}
+	}
+}
+
+func TestRuleSetSources(t *testing.T) {
+	s := Settings{
+		Regions: []string{" RU", "ru", "xx", "kz"},
+		RuleSets: []RuleSet{
+			{Tag: "ads", Type: "remote", URL: "https://example.com/ads.srs"},
+			{Tag: "json", Type: "remote", URL: "https://example.com/rules.json"},
+			{Tag: "corp", Type: "local", Path: "/etc/corp.srs"},
+		},
+	}
+	tags := []string{}
+	for _, src := range ruleSetSources(s) {
+		tags = append(tags, src.Tag)
+	}
+	if want := []string{"geoip-ru", "geoip-kz", "ads"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("sources %v, want %v", tags, want)
+	}
+}
+
+func TestRefreshRuleSets(t *testing.T) {
+	var mu sync.Mutex
+	body, etag, requests := testSrs(1, "v1"), `"v1"`, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write(body)
+	}))
+	defer srv.Close()
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+
+	a := ruleSetApp(t)
+	a.Settings.RuleSets = []RuleSet{{Tag: "ads", Type: "remote", URL: srv.URL + "/ads.srs"}}
+	a.Settings.UserRules = []UserRule{{ID: "1", Type: "rule_set", Values: []string{"ads"}, Outbound: "block"}}
+
+	if n, err := a.refreshRuleSets(false); n != 1 || err != nil {
+		t.Fatalf("first refresh: %d, %v", n, err)
+	}
+	if data, _ := os.ReadFile(a.getSrsPath("ads")); !bytes.Equal(data, testSrs(1, "v1")) {
+		t.Error("downloaded rule-set not stored")
+	}
+
+	// A fresh copy is not downloaded again unless forced.
+	if n, err := a.refreshRuleSets(false); n != 0 || err != nil || count() != 1 {
+		t.Errorf("fresh refresh: %d, %v, %d requests", n, err, count())
+	}
+	if n, err := a.refreshRuleSets(true); n != 0 || err != nil || count() != 2 {
+		t.Errorf("not modified: %d, %v, %d requests", n, err, count())
+	}
+	status := a.GetRuleSetStatus()
+	if len(status) != 1 || !status[0].Cached || status[0].Embedded || status[0].Version != 1 {
+		t.Errorf("status %+v", status)
+	}
+
+	// A corrupt download keeps the cached copy.
+	mu.Lock()
+	body, etag = []byte("<html>captive portal</html>"), `"v2"`
+	mu.Unlock()
+	if _, err := a.refreshRuleSets(true); err == nil {
+		t.Error("corrupt rule-set accepted")
+	}
+	if data, _ := os.ReadFile(a.getSrsPath("ads")); !bytes.Equal(data, testSrs(1, "v1")) {
+		t.Error("cached rule-set replaced by a corrupt download")
+	}
+
+	ob, _, _ := parseProfileKey(goldenVlessLink("tcp", "reality"))
+	config, err := a.builder().build(ob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sbRuleSet{Tag: "ads", Type: "local", Format: "binary", Path: a.getSrsPath("ads")}
+	if len(config.Route.RuleSet) != 1 || !reflect.DeepEqual(config.Route.RuleSet[0], want) {
+		t.Errorf("rule-sets %+v, want %+v", config.Route.RuleSet, want)
+	}
+}
@@ -24,11 +24,7 @@ func (a *App) GetRunningState() bool {
 }
 
 func (a *App) StartVless(vlessLink string) string {
-	a.shutdownWg.Wait()
-
-	if err := a.checkAndInstallCore(); err != nil {
-		msg := "Core installation failed: " + err.Error()
-		a.log(msg)
+	if msg := a.prepareCore(); msg != "" {
 		return msg
 	}
 
@@ -38,15 +34,43 @@ func (a *App) StartVless(vlessLink string) string {
 		return "Already running"
 	}
 
+	var selectedTag string
 	for _, p := range a.Profiles {
 		if p.Key == vlessLink {
-			a.Settings.LastProfileID = p.ID
-			a.SaveSettings(a.Settings)
+			a.setLastProfile(p.ID)
+			if p.Outbound != nil {
+				selectedTag = memberTag(p)
+			}
 			break
 		}
 	}
 	a.cmdLock.Unlock()
 
+	res := a.runCore(func() (string, error) {
+		return a.configForKey(vlessLink)
+	})
+
+	// The cache file restores the previous selector choice over the default.
+	if res == "Connected" && selectedTag != "" {
+		if err := selectOutbound(selectedTag); err != nil {
+			a.log("Failed to select profile: " + err.Error())
+		}
+	}
+	return res
+}
+
+func (a *App) prepareCore() string {
+	a.shutdownWg.Wait()
+
+	if err := a.checkAndInstallCore(); err != nil {
+		msg := "Core installation failed: " + err.Error()
+		a.log(msg)
+		return msg
+	}
+	return ""
+}
+
+func (a *App) runCore(generate func() (string, error)) string {
 	for i := 0; i < 5; i++ {
 		conn, err := net.DialTimeout("tcp", "127.0.0.1:9090", 200*time.Millisecond)
 		if err != nil {
@@ -61,7 +85,7 @@ func (a *App) StartVless(vlessLink string) string {
 		return "Core missing"
 	}
 
-	if a.Settings.RunMode == "tun" {
+	if a.settingsSnapshot().RunMode == "tun" {
 		if err := a.ensurePermissions(binPath); err != nil {
 			a.log("Error: Admin permissions required for TUN mode")
 			return "Permission denied"
@@ -69,7 +93,7 @@ func (a *App) StartVless(vlessLink string) string {
 	}
 
 	workDir := a.getAppDataDir()
-	configJSON, err := a.generateConfig(vlessLink)
+	configJSON, err := generate()
 	if err != nil {
 		a.log("Config Gen Error: " + err.Error())
 		return "Config error: " + err.Error()
@@ -156,7 +180,7 @@ func (a *App) StartVless(vlessLink string) string {
 			wailsRuntime.EventsEmit(a.ctx, "connection_status", "disconnected")
 			a.updateTrayState(false)
 
-			if a.Settings.RunMode == "proxy" {
+			if a.settingsSnapshot().RunMode == "proxy" {
 				a.setSystemProxy(false, 0)
 			}
 		}
@@ -171,12 +195,14 @@ func (a *App) StartVless(vlessLink string) string {
 	}
 	a.cmdLock.Unlock()
 
-	if a.Settings.RunMode == "proxy" {
-		if err := a.setSystemProxy(true, a.Settings.MixedPort); err != nil {
+	if settings := a.settingsSnapshot(); settings.RunMode == "proxy" {
+		if err := a.setSystemProxy(true, settings.MixedPort); err != nil {
 			a.log("Failed to set system proxy: " + err.Error())
 		}
 	}
 
+	os.WriteFile(a.getLastConfigPath(), []byte(configJSON), 0644)
+
 	a.startStatsCollector()
 	wailsRuntime.EventsEmit(a.ctx, "connection_status", "connected")
 	return "Connected"
@@ -185,7 +211,7 @@ func (a *App) StartVless(vlessLink string) string {
 func (a *App) StopVless() string {
 	a.stopStatsCollector()
 
-	if a.Settings.RunMode == "proxy" {
+	if a.settingsSnapshot().RunMode == "proxy" {
 		a.setSystemProxy(false, 0)
 	}
 
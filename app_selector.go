@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func (a *App) generateSelectorConfig(selected Profile) (string, error) {
+	if _, err := a.detourChain(selected); err != nil {
+		return "", err
+	}
+
+	members := []groupMember{}
+	for _, p := range a.Profiles {
+		if p.Outbound == nil {
+			continue
+		}
+		members = append(members, profileMember(p))
+	}
+
+	b := a.builder()
+	config, err := b.buildSelector(members, memberTag(selected))
+	if err != nil {
+		return "", err
+	}
+	for _, s := range b.skipped {
+		a.log("Selector: skipped " + s)
+	}
+	return a.marshalConfig(config)
+}
+
+func selectOutbound(tag string) error {
+	body, _ := json.Marshal(map[string]string{"name": tag})
+	req, err := http.NewRequest(http.MethodPut, "http://127.0.0.1:9090/proxies/"+url.PathEscape(selectorTag), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("clash api: %s %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// SwitchProfile fails for profiles added after connecting; the UI then
+// reconnects.
+func (a *App) SwitchProfile(profileID string) string {
+	if !a.GetRunningState() {
+		return "Error: not running"
+	}
+	if a.settingsSnapshot().ConnectMode == "failover" {
+		return "Error: servers are picked automatically in failover mode"
+	}
+
+	profile, ok := a.findProfile(profileID)
+	if !ok {
+		return "Error: profile not found"
+	}
+
+	if err := selectOutbound(memberTag(profile)); err != nil {
+		a.log("Switch failed: " + err.Error())
+		return "Error: " + err.Error()
+	}
+
+	a.log("Switched to " + profile.Name)
+	a.setLastProfile(profile.ID)
+	return "OK"
+}
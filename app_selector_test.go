@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestSelectorTag(t *testing.T) {
+	ob, name, err := parseProfileKey(goldenVlessLink("tcp", "reality"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := newConfigBuilder(Settings{RunMode: "proxy", RoutingMode: "global", MixedPort: 2080})
+	config, err := b.buildSelector([]groupMember{{Tag: "proxy-0", Name: name, Outbound: ob}}, "proxy-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// selectOutbound addresses the selector by this tag.
+	if sel := config.Outbounds[0]; sel.Type != "selector" || sel.Tag != selectorTag {
+		t.Errorf("first outbound is %s %q, want the selector tagged %q", sel.Type, sel.Tag, selectorTag)
+	}
+}
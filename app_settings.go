@@ -6,6 +6,9 @@ import (
 )
 
 func (a *App) LoadSettings() Settings {
+	a.settingsLock.Lock()
+	defer a.settingsLock.Unlock()
+
 	data, err := os.ReadFile(a.getSettingsPath())
 	if err == nil {
 		json.Unmarshal(data, &a.Settings)
@@ -16,30 +19,68 @@ func (a *App) LoadSettings() Settings {
 	if a.Settings.RunMode == "" {
 		a.Settings.RunMode = "tun"
 	}
+	if a.Settings.ConnectMode == "" {
+		a.Settings.ConnectMode = "profile"
+	}
 	if a.Settings.MixedPort == 0 {
 		a.Settings.MixedPort = 2080
 	}
-	if len(a.Settings.RuDomains) == 0 {
-		a.Settings.RuDomains = defaultRuDomains
+	migrateRuDomains(&a.Settings)
+	for i, r := range a.Settings.UserRules {
+		if r.Value != "" && len(r.Values) == 0 && len(r.Conditions) == 0 {
+			a.Settings.UserRules[i].Values = []string{r.Value}
+			a.Settings.UserRules[i].Value = ""
+		}
+	}
+	if a.Settings.IPv6 == "" {
+		a.Settings.IPv6 = "off"
+	}
+	if len(a.Settings.DNS.Servers) == 0 {
+		a.Settings.DNS = defaultDNSSettings()
 	}
 	return a.Settings
 }
 
 func (a *App) SaveSettings(s Settings) string {
+	a.settingsLock.Lock()
 	a.Settings = s
-	data, err := json.MarshalIndent(a.Settings, "", "  ")
+	err := a.writeSettings()
+	a.settingsLock.Unlock()
 	if err != nil {
 		return "Error"
 	}
-	os.WriteFile(a.getSettingsPath(), data, 0644)
 
 	if s.AutoConnect {
 		a.EnableAutostart()
 	} else {
 		a.DisableAutostart()
 	}
+	// Newly picked regions and rule-sets work offline right away.
+	a.ensureEmbeddedRuleSets()
 
 	return "Saved"
 }
 
-func (a *App) GetSettings() Settings { return a.Settings }
+func (a *App) GetSettings() Settings { return a.settingsSnapshot() }
+
+func (a *App) setLastProfile(id string) {
+	a.settingsLock.Lock()
+	defer a.settingsLock.Unlock()
+	a.Settings.LastProfileID = id
+	a.writeSettings()
+}
+
+// writeSettings is called with settingsLock held so saves don't interleave.
+func (a *App) writeSettings() error {
+	data, err := json.MarshalIndent(a.Settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(a.getSettingsPath(), data, 0644)
+}
+
+func (a *App) settingsSnapshot() Settings {
+	a.settingsLock.Lock()
+	defer a.settingsLock.Unlock()
+	return a.Settings
+}
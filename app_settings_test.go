@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestSetLastProfile(t *testing.T) {
+	a := ruleSetApp(t)
+	var wg sync.WaitGroup
+	for _, id := range []string{"a", "b", "c"} {
+		wg.Add(2)
+		go func(id string) {
+			defer wg.Done()
+			a.setLastProfile(id)
+		}(id)
+		go func() {
+			defer wg.Done()
+			ruleSetSources(a.settingsSnapshot())
+		}()
+	}
+	wg.Wait()
+
+	a.setLastProfile("d")
+	data, err := os.ReadFile(a.getSettingsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Settings
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.LastProfileID != "d" || a.GetSettings().LastProfileID != "d" {
+		t.Errorf("last profile saved %q, in memory %q", saved.LastProfileID, a.GetSettings().LastProfileID)
+	}
+}
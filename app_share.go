@@ -0,0 +1,327 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Query parameters are sorted so a profile always produces the same link.
+func shareLink(ob *Outbound, name string) (string, error) {
+	if ob.Raw != nil {
+		return "", fmt.Errorf("raw sing-box outbounds have no share link")
+	}
+
+	switch ob.Protocol {
+	case "vless":
+		return vlessShareLink(ob, name), nil
+	case "trojan":
+		return trojanShareLink(ob, name), nil
+	case "vmess":
+		return vmessShareLink(ob, name)
+	case "shadowsocks":
+		return shadowsocksLink(ob, name), nil
+	case "hysteria2":
+		return hysteria2ShareLink(ob, name), nil
+	case "tuic":
+		return tuicShareLink(ob, name), nil
+	case "wireguard":
+		return "", fmt.Errorf("WireGuard profiles have no share link, export the .conf instead")
+	}
+	return "", fmt.Errorf("unsupported protocol: %s", ob.Protocol)
+}
+
+func buildShareLink(scheme string, user *url.Userinfo, ob *Outbound, q url.Values, name string) string {
+	u := url.URL{
+		Scheme:   scheme,
+		User:     user,
+		Host:     net.JoinHostPort(ob.Server, strconv.Itoa(ob.Port)),
+		RawQuery: q.Encode(),
+	}
+	link := u.String()
+	if name != "" {
+		link += "#" + url.PathEscape(name)
+	}
+	return link
+}
+
+func setParam(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
+
+func streamQuery(ob *Outbound) url.Values {
+	q := url.Values{}
+
+	security := "none"
+	if t := ob.TLS; t != nil {
+		security = "tls"
+		if t.Reality {
+			security = "reality"
+		}
+		setParam(q, "sni", t.ServerName)
+		setParam(q, "fp", t.Fingerprint)
+		setParam(q, "alpn", strings.Join(t.ALPN, ","))
+		setParam(q, "pbk", t.PublicKey)
+		setParam(q, "sid", t.ShortID)
+		if t.Insecure {
+			q.Set("allowInsecure", "1")
+		}
+		if f := t.Fragment; f != nil {
+			if f.Fragment {
+				q.Set("fragment", "1")
+			}
+			if f.RecordFragment {
+				q.Set("record_fragment", "1")
+			}
+			setParam(q, "fragment_fallback_delay", f.FallbackDelay)
+		}
+		if t.ECH {
+			switch {
+			case t.ECHConfig != "":
+				q.Set("ech", t.ECHConfig)
+			case t.ECHQueryServerName != "":
+				q.Set("ech", t.ECHQueryServerName)
+			default:
+				q.Set("ech", "1")
+			}
+		}
+	}
+	q.Set("security", security)
+
+	q.Set("type", "tcp")
+	if tr := ob.Transport; tr != nil {
+		q.Set("type", tr.Type)
+		setParam(q, "path", tr.Path)
+		setParam(q, "host", tr.Host)
+		setParam(q, "serviceName", tr.ServiceName)
+		setParam(q, "mode", tr.Mode)
+		if tr.MaxEarlyData > 0 {
+			q.Set("ed", strconv.Itoa(tr.MaxEarlyData))
+			setParam(q, "eh", tr.EarlyDataHeader)
+		}
+	}
+	setMuxQuery(q, ob.Multiplex)
+	return q
+}
+
+func setMuxQuery(q url.Values, mux *MultiplexOptions) {
+	if mux == nil || !mux.Enabled {
+		return
+	}
+	q.Set("mux", "1")
+	setParam(q, "mux", mux.Protocol)
+	if mux.Padding {
+		q.Set("mux_padding", "1")
+	}
+	for key, v := range map[string]int{
+		"mux_max_connections": mux.MaxConnections,
+		"mux_min_streams":     mux.MinStreams,
+		"mux_max_streams":     mux.MaxStreams,
+		"mux_up":              mux.BrutalUpMbps,
+		"mux_down":            mux.BrutalDownMbps,
+	} {
+		if v > 0 {
+			q.Set(key, strconv.Itoa(v))
+		}
+	}
+}
+
+func vlessShareLink(ob *Outbound, name string) string {
+	q := streamQuery(ob)
+	q.Set("encryption", "none")
+	setParam(q, "flow", ob.Credentials.Flow)
+	return buildShareLink("vless", url.User(ob.Credentials.UUID), ob, q, name)
+}
+
+func trojanShareLink(ob *Outbound, name string) string {
+	return buildShareLink("trojan", url.User(ob.Credentials.Password), ob, streamQuery(ob), name)
+}
+
+func vmessShareLink(ob *Outbound, name string) (string, error) {
+	vm := vmessLink{
+		V:    "2",
+		Ps:   name,
+		Add:  ob.Server,
+		Port: flexString(strconv.Itoa(ob.Port)),
+		ID:   ob.Credentials.UUID,
+		Aid:  flexString(strconv.Itoa(ob.Credentials.AlterID)),
+		Scy:  ob.Credentials.Method,
+		Net:  "tcp",
+		Type: "none",
+	}
+	if tr := ob.Transport; tr != nil {
+		vm.Net = tr.Type
+		if tr.Type == "http" {
+			vm.Net = "h2"
+		}
+		vm.Host = tr.Host
+		vm.Path = tr.Path
+		if tr.Type == "grpc" && tr.ServiceName != "" {
+			vm.Path = tr.ServiceName
+		}
+	}
+	if t := ob.TLS; t != nil {
+		vm.TLS = "tls"
+		vm.SNI = t.ServerName
+		vm.ALPN = strings.Join(t.ALPN, ",")
+		vm.FP = t.Fingerprint
+	}
+
+	data, err := json.Marshal(vm)
+	if err != nil {
+		return "", err
+	}
+	return "vmess://" + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// 2022 ciphers use plain userinfo as SIP022 requires.
+func shadowsocksLink(ob *Outbound, name string) string {
+	var userinfo string
+	if strings.HasPrefix(ob.Credentials.Method, "2022-") {
+		userinfo = url.UserPassword(ob.Credentials.Method, ob.Credentials.Password).String()
+	} else {
+		userinfo = base64.RawURLEncoding.EncodeToString([]byte(ob.Credentials.Method + ":" + ob.Credentials.Password))
+	}
+
+	link := "ss://" + userinfo + "@" + net.JoinHostPort(ob.Server, strconv.Itoa(ob.Port))
+	q := url.Values{}
+	if ss := ob.Shadowsocks; ss != nil && ss.Plugin != "" {
+		plugin := ss.Plugin
+		if ss.PluginOpts != "" {
+			plugin += ";" + ss.PluginOpts
+		}
+		q.Set("plugin", plugin)
+	}
+	setMuxQuery(q, ob.Multiplex)
+	if len(q) > 0 {
+		link += "/?" + q.Encode()
+	}
+	if name != "" {
+		link += "#" + url.PathEscape(name)
+	}
+	return link
+}
+
+func hysteria2ShareLink(ob *Outbound, name string) string {
+	hy := ob.Hysteria2
+	q := url.Values{}
+	if t := ob.TLS; t != nil {
+		setParam(q, "sni", t.ServerName)
+		setParam(q, "alpn", strings.Join(t.ALPN, ","))
+		if t.Insecure {
+			q.Set("insecure", "1")
+		}
+	}
+	if hy.ObfsType != "" {
+		q.Set("obfs", hy.ObfsType)
+		q.Set("obfs-password", hy.ObfsPassword)
+	}
+	if hy.UpMbps > 0 {
+		q.Set("upmbps", strconv.Itoa(hy.UpMbps))
+	}
+	if hy.DownMbps > 0 {
+		q.Set("downmbps", strconv.Itoa(hy.DownMbps))
+	}
+
+	ports := []string{}
+	coveredByRange := false
+	for _, r := range hy.Ports {
+		from, to, _ := strings.Cut(r, ":")
+		if from == to {
+			ports = append(ports, from)
+			continue
+		}
+		start, _ := strconv.Atoi(from)
+		end, _ := strconv.Atoi(to)
+		if ob.Port >= start && ob.Port <= end {
+			coveredByRange = true
+		}
+		ports = append(ports, from+"-"+to)
+	}
+	if !coveredByRange {
+		ports = append([]string{strconv.Itoa(ob.Port)}, ports...)
+	}
+
+	host := ob.Server
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	link := "hysteria2://" + url.User(ob.Credentials.Password).String() + "@" + host + ":" + strings.Join(ports, ",") + "/"
+	if len(q) > 0 {
+		link += "?" + q.Encode()
+	}
+	if name != "" {
+		link += "#" + url.PathEscape(name)
+	}
+	return link
+}
+
+func tuicShareLink(ob *Outbound, name string) string {
+	q := url.Values{}
+	q.Set("congestion_control", ob.TUIC.CongestionControl)
+	q.Set("udp_relay_mode", ob.TUIC.UDPRelayMode)
+	if ob.TUIC.ZeroRTT {
+		q.Set("reduce_rtt", "1")
+	}
+	if t := ob.TLS; t != nil {
+		setParam(q, "sni", t.ServerName)
+		setParam(q, "alpn", strings.Join(t.ALPN, ","))
+		if t.Insecure {
+			q.Set("allow_insecure", "1")
+		}
+		if t.DisableSNI {
+			q.Set("disable_sni", "1")
+		}
+	}
+	return buildShareLink("tuic", url.UserPassword(ob.Credentials.UUID, ob.Credentials.Password), ob, q, name)
+}
+
+func (a *App) ExportProfiles(ids []string) string {
+	wanted := map[string]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var links []string
+	for _, p := range a.Profiles {
+		if !wanted[p.ID] {
+			continue
+		}
+		ob := a.profileOutbound(p.ID)
+		if ob == nil {
+			a.log("Export: skipping " + p.Name + ": invalid key")
+			continue
+		}
+		link, err := shareLink(ob.withOptions(p.Options), p.Name)
+		if err != nil {
+			a.log("Export: skipping " + p.Name + ": " + err.Error())
+			continue
+		}
+		links = append(links, link)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(strings.Join(links, "\n")))
+}
+
+func (a *App) GetShareLink(profileID string) string {
+	for _, p := range a.Profiles {
+		if p.ID != profileID {
+			continue
+		}
+		ob := a.profileOutbound(p.ID)
+		if ob == nil {
+			return "Error: invalid key"
+		}
+		link, err := shareLink(ob.withOptions(p.Options), p.Name)
+		if err != nil {
+			return "Error: " + err.Error()
+		}
+		return link
+	}
+	return "Error: profile not found"
+}
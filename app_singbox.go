@@ -0,0 +1,265 @@
+package main
+
+import "encoding/json"
+
+type sbConfig struct {
+	Log          *sbLog          `json:"log,omitempty"`
+	DNS          *sbDNS          `json:"dns,omitempty"`
+	Inbounds     []sbInbound     `json:"inbounds"`
+	Outbounds    []sbOutbound    `json:"outbounds"`
+	Endpoints    []sbEndpoint    `json:"endpoints,omitempty"`
+	Route        *sbRoute        `json:"route,omitempty"`
+	Experimental *sbExperimental `json:"experimental,omitempty"`
+}
+
+type sbLog struct {
+	Level     string `json:"level,omitempty"`
+	Timestamp bool   `json:"timestamp,omitempty"`
+}
+
+type sbDNS struct {
+	Servers          []sbDNSServer `json:"servers"`
+	Rules            []sbDNSRule   `json:"rules,omitempty"`
+	Final            string        `json:"final,omitempty"`
+	Strategy         string        `json:"strategy,omitempty"`
+	DisableCache     bool          `json:"disable_cache,omitempty"`
+	DisableExpire    bool          `json:"disable_expire,omitempty"`
+	IndependentCache bool          `json:"independent_cache,omitempty"`
+	CacheCapacity    int           `json:"cache_capacity,omitempty"`
+}
+
+type sbDNSServer struct {
+	Tag            string `json:"tag"`
+	Type           string `json:"type"`
+	Server         string `json:"server,omitempty"`
+	ServerPort     int    `json:"server_port,omitempty"`
+	Path           string `json:"path,omitempty"`
+	Detour         string `json:"detour,omitempty"`
+	DomainResolver string `json:"domain_resolver,omitempty"`
+	Inet4Range     string `json:"inet4_range,omitempty"`
+	Inet6Range     string `json:"inet6_range,omitempty"`
+}
+
+type sbDNSRule struct {
+	DomainSuffix []string `json:"domain_suffix,omitempty"`
+	QueryType    []string `json:"query_type,omitempty"`
+	Server       string   `json:"server"`
+}
+
+type sbInbound struct {
+	Type                     string   `json:"type"`
+	Tag                      string   `json:"tag"`
+	Listen                   string   `json:"listen,omitempty"`
+	ListenPort               int      `json:"listen_port,omitempty"`
+	InterfaceName            string   `json:"interface_name,omitempty"`
+	Address                  []string `json:"address,omitempty"`
+	MTU                      int      `json:"mtu,omitempty"`
+	AutoRoute                bool     `json:"auto_route,omitempty"`
+	StrictRoute              bool     `json:"strict_route,omitempty"`
+	RouteExcludeAddress      []string `json:"route_exclude_address,omitempty"`
+	IncludeUID               []int    `json:"include_uid,omitempty"`
+	ExcludeUID               []int    `json:"exclude_uid,omitempty"`
+	Stack                    string   `json:"stack,omitempty"`
+	Sniff                    bool     `json:"sniff,omitempty"`
+	SniffOverrideDestination bool     `json:"sniff_override_destination,omitempty"`
+}
+
+type sbOutbound struct {
+	Type           string   `json:"type"`
+	Tag            string   `json:"tag"`
+	Server         string   `json:"server,omitempty"`
+	ServerPort     int      `json:"server_port,omitempty"`
+	ServerPorts    []string `json:"server_ports,omitempty"`
+	UUID           string   `json:"uuid,omitempty"`
+	Password       string   `json:"password,omitempty"`
+	Method         string   `json:"method,omitempty"`
+	Security       string   `json:"security,omitempty"`
+	AlterID        int      `json:"alter_id,omitempty"`
+	Flow           string   `json:"flow,omitempty"`
+	PacketEncoding string   `json:"packet_encoding,omitempty"`
+	Plugin         string   `json:"plugin,omitempty"`
+	PluginOpts     string   `json:"plugin_opts,omitempty"`
+
+	CongestionControl string  `json:"congestion_control,omitempty"`
+	UDPRelayMode      string  `json:"udp_relay_mode,omitempty"`
+	ZeroRTTHandshake  bool    `json:"zero_rtt_handshake,omitempty"`
+	UpMbps            int     `json:"up_mbps,omitempty"`
+	DownMbps          int     `json:"down_mbps,omitempty"`
+	Obfs              *sbObfs `json:"obfs,omitempty"`
+
+	TLS       *sbTLS       `json:"tls,omitempty"`
+	Transport *sbTransport `json:"transport,omitempty"`
+	Multiplex *sbMultiplex `json:"multiplex,omitempty"`
+
+	Detour string `json:"detour,omitempty"`
+
+	Outbounds []string `json:"outbounds,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	Interval  string   `json:"interval,omitempty"`
+	Tolerance int      `json:"tolerance,omitempty"`
+	Default   string   `json:"default,omitempty"`
+
+	// raw is a user supplied outbound written out verbatim.
+	raw map[string]interface{}
+}
+
+func (o sbOutbound) MarshalJSON() ([]byte, error) {
+	if o.raw != nil {
+		return json.Marshal(o.raw)
+	}
+	type plain sbOutbound
+	return json.Marshal(plain(o))
+}
+
+func (o *sbOutbound) setTag(tag string) {
+	o.Tag = tag
+	if o.raw != nil {
+		o.raw["tag"] = tag
+	}
+}
+
+func (o *sbOutbound) setDetour(detour string) {
+	o.Detour = detour
+	if o.raw != nil && detour != "" {
+		o.raw["detour"] = detour
+	}
+}
+
+type sbObfs struct {
+	Type     string `json:"type"`
+	Password string `json:"password,omitempty"`
+}
+
+type sbTLS struct {
+	Enabled               bool       `json:"enabled"`
+	ServerName            string     `json:"server_name,omitempty"`
+	Insecure              bool       `json:"insecure,omitempty"`
+	DisableSNI            bool       `json:"disable_sni,omitempty"`
+	ALPN                  []string   `json:"alpn,omitempty"`
+	UTLS                  *sbUTLS    `json:"utls,omitempty"`
+	ECH                   *sbECH     `json:"ech,omitempty"`
+	Fragment              bool       `json:"fragment,omitempty"`
+	RecordFragment        bool       `json:"record_fragment,omitempty"`
+	FragmentFallbackDelay string     `json:"fragment_fallback_delay,omitempty"`
+	Reality               *sbReality `json:"reality,omitempty"`
+}
+
+type sbUTLS struct {
+	Enabled     bool   `json:"enabled"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+type sbECH struct {
+	Enabled         bool     `json:"enabled"`
+	Config          []string `json:"config,omitempty"`
+	QueryServerName string   `json:"query_server_name,omitempty"`
+}
+
+type sbReality struct {
+	Enabled   bool   `json:"enabled"`
+	PublicKey string `json:"public_key"`
+	ShortID   string `json:"short_id,omitempty"`
+}
+
+type sbTransport struct {
+	Type                string            `json:"type"`
+	Host                string            `json:"host,omitempty"`
+	Path                string            `json:"path,omitempty"`
+	Headers             map[string]string `json:"headers,omitempty"`
+	ServiceName         string            `json:"service_name,omitempty"`
+	MaxEarlyData        int               `json:"max_early_data,omitempty"`
+	EarlyDataHeaderName string            `json:"early_data_header_name,omitempty"`
+}
+
+type sbMultiplex struct {
+	Enabled        bool      `json:"enabled"`
+	Protocol       string    `json:"protocol,omitempty"`
+	MaxConnections int       `json:"max_connections,omitempty"`
+	MinStreams     int       `json:"min_streams,omitempty"`
+	MaxStreams     int       `json:"max_streams,omitempty"`
+	Padding        bool      `json:"padding,omitempty"`
+	Brutal         *sbBrutal `json:"brutal,omitempty"`
+}
+
+type sbBrutal struct {
+	Enabled  bool `json:"enabled"`
+	UpMbps   int  `json:"up_mbps"`
+	DownMbps int  `json:"down_mbps"`
+}
+
+type sbEndpoint struct {
+	Type       string            `json:"type"`
+	Tag        string            `json:"tag"`
+	MTU        int               `json:"mtu,omitempty"`
+	Address    []string          `json:"address"`
+	PrivateKey string            `json:"private_key"`
+	Peers      []sbWireGuardPeer `json:"peers"`
+	Detour     string            `json:"detour,omitempty"`
+}
+
+type sbWireGuardPeer struct {
+	Address                     string   `json:"address"`
+	Port                        int      `json:"port"`
+	PublicKey                   string   `json:"public_key"`
+	PreSharedKey                string   `json:"pre_shared_key,omitempty"`
+	AllowedIPs                  []string `json:"allowed_ips"`
+	PersistentKeepaliveInterval int      `json:"persistent_keepalive_interval,omitempty"`
+}
+
+type sbRoute struct {
+	RuleSet               []sbRuleSet `json:"rule_set,omitempty"`
+	Rules                 []sbRule    `json:"rules"`
+	AutoDetectInterface   bool        `json:"auto_detect_interface,omitempty"`
+	Final                 string      `json:"final,omitempty"`
+	DefaultDomainResolver string      `json:"default_domain_resolver,omitempty"`
+}
+
+type sbRuleSet struct {
+	Tag            string `json:"tag"`
+	Type           string `json:"type"`
+	Format         string `json:"format"`
+	URL            string `json:"url,omitempty"`
+	Path           string `json:"path,omitempty"`
+	DownloadDetour string `json:"download_detour,omitempty"`
+}
+
+type sbRule struct {
+	Type  string   `json:"type,omitempty"`
+	Mode  string   `json:"mode,omitempty"`
+	Rules []sbRule `json:"rules,omitempty"`
+
+	Inbound       []string `json:"inbound,omitempty"`
+	Network       []string `json:"network,omitempty"`
+	Protocol      []string `json:"protocol,omitempty"`
+	Domain        []string `json:"domain,omitempty"`
+	DomainSuffix  []string `json:"domain_suffix,omitempty"`
+	DomainKeyword []string `json:"domain_keyword,omitempty"`
+	DomainRegex   []string `json:"domain_regex,omitempty"`
+	SourceIPCIDR  []string `json:"source_ip_cidr,omitempty"`
+	IPCIDR        []string `json:"ip_cidr,omitempty"`
+	IPIsPrivate   bool     `json:"ip_is_private,omitempty"`
+	IPVersion     int      `json:"ip_version,omitempty"`
+	Port          []int    `json:"port,omitempty"`
+	PortRange     []string `json:"port_range,omitempty"`
+	ProcessName   []string `json:"process_name,omitempty"`
+	RuleSet       []string `json:"rule_set,omitempty"`
+	Invert        bool     `json:"invert,omitempty"`
+
+	Action   string `json:"action,omitempty"`
+	Outbound string `json:"outbound,omitempty"`
+}
+
+type sbExperimental struct {
+	ClashAPI  *sbClashAPI  `json:"clash_api,omitempty"`
+	CacheFile *sbCacheFile `json:"cache_file,omitempty"`
+}
+
+type sbClashAPI struct {
+	ExternalController string `json:"external_controller"`
+}
+
+type sbCacheFile struct {
+	Enabled     bool `json:"enabled"`
+	StoreFakeIP bool `json:"store_fakeip,omitempty"`
+	StoreRDRC   bool `json:"store_rdrc,omitempty"`
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRawOutboundMarshal(t *testing.T) {
+	o := sbOutbound{Type: "socks", raw: map[string]interface{}{"type": "socks", "tag": "mine", "server": "198.51.100.1"}}
+	o.setTag("proxy")
+	o.setDetour("")
+	out, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"server":"198.51.100.1","tag":"proxy","type":"socks"}`; string(out) != want {
+		t.Errorf("got %s, want %s", out, want)
+	}
+
+	o.setDetour("proxy-0")
+	out, _ = json.Marshal(o)
+	if want := `{"detour":"proxy-0","server":"198.51.100.1","tag":"proxy","type":"socks"}`; string(out) != want {
+		t.Errorf("got %s, want %s", out, want)
+	}
+}
+
+func TestTypedOutboundMarshal(t *testing.T) {
+	out, err := json.Marshal(sbOutbound{Type: "direct", Tag: "direct"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"type":"direct","tag":"direct"}`; string(out) != want {
+		t.Errorf("got %s, want %s", out, want)
+	}
+}
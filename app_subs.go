@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -101,28 +100,20 @@ func (a *App) UpdateSubscription(subID string) string {
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
-	content := string(body)
 
-	decoded, err := base64.StdEncoding.DecodeString(content)
-	if err == nil {
-		content = string(decoded)
-	} else {
-		decoded, err = base64.URLEncoding.DecodeString(content)
-		if err == nil {
-			content = string(decoded)
-		}
-	}
+	links, skipped := parseSubscriptionContent(body)
+	a.logSkipped("Subscription", skipped)
 
-	var newLinks []string
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "vless://") {
-			newLinks = append(newLinks, line)
+	var newProfiles []Profile
+	for _, link := range links {
+		p, err := newProfile(link, "", subID)
+		if err != nil {
+			continue
 		}
+		newProfiles = append(newProfiles, p)
 	}
 
-	if len(newLinks) == 0 {
+	if len(newProfiles) == 0 {
 		return "No valid links found"
 	}
 
@@ -134,31 +125,98 @@ func (a *App) UpdateSubscription(subID string) string {
 			tempProfiles = append(tempProfiles, p)
 		}
 	}
-	a.Profiles = tempProfiles
-
-	for _, link := range newLinks {
-		u, _ := url.Parse(link)
-		name := u.Fragment
-		if name == "" {
-			name = u.Hostname()
-		}
-		name, _ = url.QueryUnescape(name)
-
-		a.Profiles = append(a.Profiles, Profile{
-			ID:             uuid.New().String(),
-			Name:           name,
-			Key:            link,
-			SubscriptionID: subID,
-			CreatedAt:      time.Now().Unix(),
-		})
-	}
+	a.Profiles = append(tempProfiles, newProfiles...)
 
 	a.SaveSubscriptions()
 	a.SaveProfiles()
 
-	return fmt.Sprintf("Updated: %d profiles", len(newLinks))
+	if len(skipped) > 0 {
+		return fmt.Sprintf("Updated: %d profiles, %d skipped (see logs)", len(newProfiles), len(skipped))
+	}
+	return fmt.Sprintf("Updated: %d profiles", len(newProfiles))
 }
 
 func (a *App) GetSubscriptions() []Subscription {
 	return a.LoadSubscriptions()
 }
+
+type sip008Document struct {
+	Version int            `json:"version"`
+	Servers []sip008Server `json:"servers"`
+}
+
+type sip008Server struct {
+	ID         string `json:"id"`
+	Remarks    string `json:"remarks"`
+	Server     string `json:"server"`
+	ServerPort int    `json:"server_port"`
+	Password   string `json:"password"`
+	Method     string `json:"method"`
+	Plugin     string `json:"plugin"`
+	PluginOpts string `json:"plugin_opts"`
+}
+
+// SIP008 JSON, Clash / Mihomo YAML, base64 and plain link lists.
+func parseSubscriptionContent(body []byte) (links, skipped []string) {
+	content := strings.TrimSpace(string(body))
+
+	if strings.HasPrefix(content, "{") {
+		var doc sip008Document
+		if err := json.Unmarshal([]byte(content), &doc); err == nil && len(doc.Servers) > 0 {
+			return sip008Links(doc), nil
+		}
+	}
+
+	if isClashConfig(content) {
+		return clashLinks(content)
+	}
+
+	if decoded, err := decodeBase64Loose(content); err == nil {
+		content = string(decoded)
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if isSupportedLink(line) {
+			links = append(links, normalizeLink(line))
+		}
+	}
+	return links, nil
+}
+
+func (a *App) logSkipped(source string, skipped []string) {
+	for _, s := range skipped {
+		a.log(source + ": skipped " + s)
+	}
+}
+
+func sip008Links(doc sip008Document) []string {
+	var links []string
+	for _, srv := range doc.Servers {
+		name := srv.Remarks
+		if name == "" {
+			name = srv.Server
+		}
+		ob := &Outbound{
+			Protocol: "shadowsocks",
+			Server:   srv.Server,
+			Port:     srv.ServerPort,
+			Credentials: Credentials{
+				Method:   strings.ToLower(srv.Method),
+				Password: srv.Password,
+			},
+		}
+		if srv.Plugin != "" {
+			plugin, ok := shadowsocksPlugins[srv.Plugin]
+			if !ok {
+				continue
+			}
+			ob.Shadowsocks = &ShadowsocksOptions{Plugin: plugin, PluginOpts: srv.PluginOpts}
+		}
+		if ob.validate() != nil {
+			continue
+		}
+		links = append(links, shadowsocksLink(ob, name))
+	}
+	return links
+}
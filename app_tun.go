@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"net"
+)
+
+type TunSettings struct {
+	MTU                 int      `json:"mtu,omitempty"`
+	Stack               string   `json:"stack,omitempty"`
+	StrictRoute         *bool    `json:"strict_route,omitempty"`
+	InterfaceName       string   `json:"interface_name,omitempty"`
+	RouteExcludeAddress []string `json:"route_exclude_address,omitempty"`
+	IncludeUID          []int    `json:"include_uid,omitempty"`
+	ExcludeUID          []int    `json:"exclude_uid,omitempty"`
+}
+
+var tunStacks = map[string]bool{"system": true, "gvisor": true, "mixed": true}
+
+func (t TunSettings) validate() error {
+	if t.MTU != 0 && (t.MTU < 576 || t.MTU > 65535) {
+		return fmt.Errorf("mtu must be between 576 and 65535")
+	}
+	if t.Stack != "" && !tunStacks[t.Stack] {
+		return fmt.Errorf("unknown stack %q", t.Stack)
+	}
+	for _, cidr := range t.RouteExcludeAddress {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("excluded address %q is not a CIDR", cidr)
+		}
+	}
+	for _, uid := range append(t.IncludeUID, t.ExcludeUID...) {
+		if uid < 0 {
+			return fmt.Errorf("invalid uid %d", uid)
+		}
+	}
+	return nil
+}
+
+func (b *configBuilder) buildTunInbound() (sbInbound, error) {
+	t := b.settings.Tun
+	if err := t.validate(); err != nil {
+		return sbInbound{}, fmt.Errorf("tun: %w", err)
+	}
+
+	tun := sbInbound{
+		Type:                     "tun",
+		Tag:                      "tun-in",
+		Address:                  []string{"172.19.0.1/30"},
+		MTU:                      9000,
+		AutoRoute:                true,
+		StrictRoute:              t.StrictRoute == nil || *t.StrictRoute,
+		Stack:                    "system",
+		InterfaceName:            t.InterfaceName,
+		RouteExcludeAddress:      t.RouteExcludeAddress,
+		Sniff:                    true,
+		SniffOverrideDestination: true,
+	}
+	if t.MTU != 0 {
+		tun.MTU = t.MTU
+	}
+	if t.Stack != "" {
+		tun.Stack = t.Stack
+	}
+	if b.ipv6Mode() != "off" {
+		// A ULA address makes auto_route capture IPv6 as well.
+		tun.Address = append(tun.Address, "fdfe:dcba:9876::1/126")
+	}
+	if b.goos == "linux" {
+		if tun.InterfaceName == "" {
+			tun.InterfaceName = "tun0"
+		}
+		tun.IncludeUID = t.IncludeUID
+		tun.ExcludeUID = t.ExcludeUID
+	}
+	return tun, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIPv6Mode(t *testing.T) {
+	for _, c := range []struct {
+		setting  string
+		mode     string
+		address  []string
+		strategy string
+	}{
+		{"", "off", []string{"172.19.0.1/30"}, "ipv4_only"},
+		{"bogus", "off", []string{"172.19.0.1/30"}, "ipv4_only"},
+		{"on", "on", []string{"172.19.0.1/30", "fdfe:dcba:9876::1/126"}, "prefer_ipv4"},
+		{"block", "block", []string{"172.19.0.1/30", "fdfe:dcba:9876::1/126"}, "ipv4_only"},
+	} {
+		b := newConfigBuilder(Settings{RunMode: "tun", IPv6: c.setting})
+		if got := b.ipv6Mode(); got != c.mode {
+			t.Errorf("%q: mode %q, want %q", c.setting, got, c.mode)
+		}
+		tun, err := b.buildTunInbound()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(tun.Address, c.address) {
+			t.Errorf("%q: tun address %v, want %v", c.setting, tun.Address, c.address)
+		}
+		if err := b.addDNS(); err != nil {
+			t.Fatal(err)
+		}
+		if b.config.DNS.Strategy != c.strategy {
+			t.Errorf("%q: dns strategy %q, want %q", c.setting, b.config.DNS.Strategy, c.strategy)
+		}
+	}
+
+	b := newConfigBuilder(Settings{IPv6: "on", DNS: DNSSettings{Strategy: "ipv6_only"}})
+	if err := b.addDNS(); err != nil {
+		t.Fatal(err)
+	}
+	if b.config.DNS.Strategy != "ipv6_only" {
+		t.Errorf("explicit strategy overridden: %q", b.config.DNS.Strategy)
+	}
+}
+
+func TestTunSettingsValidate(t *testing.T) {
+	strict := false
+	valid := TunSettings{
+		MTU:                 1400,
+		Stack:               "mixed",
+		StrictRoute:         &strict,
+		RouteExcludeAddress: []string{"10.0.0.0/8", "fd00::/8"},
+		IncludeUID:          []int{0, 1000},
+	}
+	if err := valid.validate(); err != nil {
+		t.Errorf("valid settings: %v", err)
+	}
+	if err := (TunSettings{}).validate(); err != nil {
+		t.Errorf("zero settings: %v", err)
+	}
+
+	for name, s := range map[string]TunSettings{
+		"mtu low":     {MTU: 575},
+		"mtu high":    {MTU: 65536},
+		"stack":       {Stack: "lwip"},
+		"exclude":     {RouteExcludeAddress: []string{"10.0.0.1"}},
+		"include uid": {IncludeUID: []int{-1}},
+		"exclude uid": {ExcludeUID: []int{-1}},
+	} {
+		if err := s.validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	b := newConfigBuilder(Settings{RunMode: "tun", Tun: TunSettings{Stack: "lwip"}})
+	if _, err := b.buildTunInbound(); err == nil {
+		t.Error("buildTunInbound accepted an unknown stack")
+	}
+}
+
+func TestBuildTunInboundDefaults(t *testing.T) {
+	uids := TunSettings{IncludeUID: []int{1000}, ExcludeUID: []int{0}}
+	for _, goos := range []string{"linux", "windows", "darwin"} {
+		b := newConfigBuilder(Settings{RunMode: "tun", Tun: uids})
+		b.goos = goos
+		tun, err := b.buildTunInbound()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tun.MTU != 9000 || tun.Stack != "system" || !tun.StrictRoute || !tun.AutoRoute {
+			t.Errorf("%s: defaults not applied: %+v", goos, tun)
+		}
+
+		linux := goos == "linux"
+		if name := tun.InterfaceName; (name == "tun0") != linux || (!linux && name != "") {
+			t.Errorf("%s: interface name %q", goos, name)
+		}
+		if hasUIDs := tun.IncludeUID != nil || tun.ExcludeUID != nil; hasUIDs != linux {
+			t.Errorf("%s: uids %v %v", goos, tun.IncludeUID, tun.ExcludeUID)
+		}
+	}
+
+	b := newConfigBuilder(Settings{RunMode: "tun", Tun: TunSettings{InterfaceName: "censaway0"}})
+	b.goos = "linux"
+	if tun, _ := b.buildTunInbound(); tun.InterfaceName != "censaway0" {
+		t.Errorf("interface name %q, want censaway0", tun.InterfaceName)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func isWireGuardConfig(key string) bool {
+	return strings.Contains(key, "[Interface]") && strings.Contains(key, "[Peer]")
+}
+
+func (a *App) ImportWireGuard(source string) string {
+	source = strings.TrimSpace(source)
+	text, name := source, ""
+	if !isWireGuardConfig(source) {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return "Read failed: " + err.Error()
+		}
+		text = string(data)
+		name = strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	}
+
+	p, err := newProfile(strings.TrimSpace(text), name, "")
+	if err != nil {
+		return "Invalid WireGuard config: " + err.Error()
+	}
+	if name == "" {
+		p.Name = "WireGuard " + p.Outbound.Server
+	}
+
+	a.Profiles = append(a.Profiles, p)
+	a.SaveProfiles()
+	return "OK"
+}
+
+// Missing keys are left for Outbound.validate to report.
+func parseWireGuardConfig(text string) (*Outbound, error) {
+	wg := &WireGuardOptions{}
+	var peer *WireGuardPeer
+	section := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			if section == "peer" {
+				wg.Peers = append(wg.Peers, WireGuardPeer{})
+				peer = &wg.Peers[len(wg.Peers)-1]
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed line %q", line)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch section {
+		case "interface":
+			switch key {
+			case "privatekey":
+				wg.PrivateKey = value
+			case "address":
+				for _, addr := range splitList(value) {
+					prefix, err := parseInterfaceAddress(addr)
+					if err != nil {
+						return nil, err
+					}
+					wg.Addresses = append(wg.Addresses, prefix)
+				}
+			case "mtu":
+				wg.MTU, _ = strconv.Atoi(value)
+			}
+		case "peer":
+			switch key {
+			case "publickey":
+				peer.PublicKey = value
+			case "presharedkey":
+				peer.PresharedKey = value
+			case "endpoint":
+				host, port, err := net.SplitHostPort(value)
+				if err != nil {
+					return nil, fmt.Errorf("bad endpoint %q", value)
+				}
+				peer.Server = host
+				peer.Port, err = strconv.Atoi(port)
+				if err != nil || peer.Port <= 0 || peer.Port > 65535 {
+					return nil, fmt.Errorf("bad endpoint port %q", value)
+				}
+			case "allowedips":
+				peer.AllowedIPs = append(peer.AllowedIPs, splitList(value)...)
+			case "persistentkeepalive":
+				peer.Keepalive, _ = strconv.Atoi(value)
+			}
+		}
+	}
+
+	ob := &Outbound{Protocol: "wireguard", WireGuard: wg}
+	for i, p := range wg.Peers {
+		if len(p.AllowedIPs) == 0 {
+			wg.Peers[i].AllowedIPs = []string{"0.0.0.0/0", "::/0"}
+		}
+	}
+	if len(wg.Peers) > 0 {
+		ob.Server, ob.Port = wg.Peers[0].Server, wg.Peers[0].Port
+	}
+	return ob, nil
+}
+
+// sing-box requires a prefix, so bare addresses get one.
+func parseInterfaceAddress(addr string) (string, error) {
+	if prefix, err := netip.ParsePrefix(addr); err == nil {
+		return prefix.String(), nil
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return "", fmt.Errorf("bad address %q", addr)
+	}
+	return netip.PrefixFrom(ip, ip.BitLen()).String(), nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const multiPeerConf = `[Interface]
+PrivateKey = yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk=
+Address = 10.0.0.2/32
+
+[Peer]
+PublicKey = xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=
+AllowedIPs = 10.0.0.0/24
+Endpoint = 203.0.113.1:51820
+
+[Peer]
+PublicKey = TrMvSoP4jYQlY6RIzBgbssQqY3vxI2Pi+y71lOWWXX0=
+AllowedIPs = 10.0.1.0/24
+Endpoint = vpn2.example.com:51820
+
+[Peer]
+PublicKey = gN65BkIKy1eCE9pP1wdc8ROUtkHLF2PfAqYdyYBz6EA=
+AllowedIPs = 10.0.2.0/24
+Endpoint = 203.0.113.1:51821
+`
+
+func TestWireGuardRoutesEveryPeerDirect(t *testing.T) {
+	ob, _, err := parseProfileKey(multiPeerConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ob.WireGuard.Peers) != 3 {
+		t.Fatalf("parsed %d peers, want 3", len(ob.WireGuard.Peers))
+	}
+
+	b := newConfigBuilder(Settings{RunMode: "tun", RoutingMode: "global", MixedPort: 2080})
+	b.goos = "linux"
+	if _, err := b.build(ob); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"203.0.113.1", "vpn2.example.com"}
+	if !reflect.DeepEqual(b.servers, want) {
+		t.Errorf("direct servers = %v, want %v", b.servers, want)
+	}
+}
+
+func TestWireGuardChainedPeersStayInTunnel(t *testing.T) {
+	ob, _, err := parseProfileKey(multiPeerConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := newConfigBuilder(Settings{RunMode: "tun", RoutingMode: "global", MixedPort: 2080})
+	if err := b.addChainedProxy("proxy-1", ob, "proxy-0"); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.servers) != 0 {
+		t.Errorf("chained peers routed direct: %v", b.servers)
+	}
+}
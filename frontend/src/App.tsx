@@ -1,5 +1,5 @@
 import React, { useState, useEffect } from 'react';
-import { StartVless, StopVless, GetProfiles, DeleteProfile, TcpPing, GetSettings, SaveSettings, GetRunningState, UrlTest, GetLogs, CheckAppUpdate } from "../wailsjs/go/main/App";
+import { StartVless, StartFailover, StopVless, SwitchProfile, GetProfiles, DeleteProfile, TcpPing, GetSettings, SaveSettings, GetRunningState, UrlTest, GetLogs, CheckAppUpdate } from "../wailsjs/go/main/App";
 import { EventsOn, EventsOff, WindowMinimise, Quit, WindowToggleMaximise } from "../wailsjs/runtime/runtime";
 import { main } from "../wailsjs/go/models";
 
@@ -125,15 +125,18 @@ function App() {
         setIsPinging(false);
     };
 
+    const isFailover = settings.connect_mode === "failover";
+    const startCore = (profile?: UIProfile) => isFailover ? StartFailover() : StartVless(profile!.key);
+
     const toggleConnection = async () => {
         if (connectionState === "disconnected") {
             const currentProfile = profiles.find(p => p.id === selectedId);
-            if (!currentProfile) { setStatus("Select profile"); return; }
+            if (!currentProfile && !isFailover) { setStatus("Select profile"); return; }
 
             setConnectionState("connecting");
             setStatus("Starting...");
 
-            const res = await StartVless(currentProfile.key);
+            const res = await startCore(currentProfile);
             if (res === "Connected") {
                 setConnectionState("connected"); setStatus("Secured");
                 setActiveSettings(settings);
@@ -152,8 +155,8 @@ function App() {
         setStatus("Restarting..."); setConnectionState("connecting");
         await StopVless();
         const currentProfile = profiles.find(p => p.id === selectedId);
-        if (currentProfile) {
-            const res = await StartVless(currentProfile.key);
+        if (currentProfile || isFailover) {
+            const res = await startCore(currentProfile);
             if (res === "Connected") {
                 setConnectionState("connected"); setStatus("Secured");
                 setActiveSettings(settings);
@@ -165,8 +168,10 @@ function App() {
 
     const handleSelectProfile = async (id: string) => {
         setSelectedId(id);
-        if (connectionState === "connected") {
-            setStatus("Switching..."); await StopVless();
+        if (connectionState === "connected" && !isFailover) {
+            setStatus("Switching...");
+            if (await SwitchProfile(id) === "OK") { setStatus("Secured"); return; }
+            await StopVless();
             const profile = profiles.find(p => p.id === id);
             if (profile) {
                 const res = await StartVless(profile.key);
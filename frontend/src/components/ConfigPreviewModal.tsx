@@ -0,0 +1,85 @@
+import React, { useState } from 'react';
+import { main } from "../../wailsjs/go/models";
+import { ClipboardSetText } from "../../wailsjs/runtime/runtime";
+
+interface Props {
+    isOpen: boolean;
+    onClose: () => void;
+    title: string;
+    preview: main.ConfigPreview | null;
+}
+
+const formatValue = (v: any) => v === undefined ? "" : JSON.stringify(v);
+
+export const ConfigPreviewModal: React.FC<Props> = ({ isOpen, onClose, title, preview }) => {
+    const [tab, setTab] = useState<"config" | "diff">("config");
+
+    if (!isOpen) return null;
+
+    const kindColor = (kind: string) => kind === "added" ? "text-green-400" : kind === "removed" ? "text-red-400" : "text-yellow-400";
+
+    return (
+        <div className="fixed inset-0 z-[100] flex items-center justify-center bg-black/80 backdrop-blur-md animate-[fadeIn_0.2s_ease-out]" onClick={onClose}>
+            <div className="w-[560px] max-h-[85vh] bg-[#0f0f13] p-6 rounded-2xl border border-white/10 shadow-[0_0_50px_-10px_rgba(0,0,0,0.8)] animate-[scaleIn_0.2s_ease-out] flex flex-col" onClick={(e) => e.stopPropagation()}>
+                <h3 className="text-sm font-bold text-white mb-4 truncate">{title}</h3>
+
+                {!preview ? (
+                    <p className="text-[11px] text-gray-500 mb-4">Generating...</p>
+                ) : preview.error && !preview.config ? (
+                    <p className="text-[11px] text-red-400 mb-4 leading-relaxed">{preview.error}</p>
+                ) : (
+                    <>
+                        {preview.check && (
+                            <div className={`text-[10px] font-mono mb-3 p-2 rounded-lg border whitespace-pre-wrap ${preview.check.ok ? "border-green-500/30 bg-green-500/5 text-green-400" : "border-red-500/30 bg-red-500/5 text-red-400"}`}>
+                                sing-box check: {preview.check.ok ? "OK" : "FAILED"}{preview.check.output ? "\n" + preview.check.output : ""}
+                            </div>
+                        )}
+                        {preview.error && <p className="text-[10px] text-red-400 mb-3">{preview.error}</p>}
+
+                        <div className="flex bg-black/40 p-0.5 rounded-lg mb-3">
+                            <button onClick={() => setTab("config")} className={`flex-1 text-[10px] py-1 rounded transition-colors ${tab === "config" ? "bg-white/10 text-white" : "text-gray-500 hover:text-gray-300"}`}>CONFIG</button>
+                            <button onClick={() => setTab("diff")} className={`flex-1 text-[10px] py-1 rounded transition-colors ${tab === "diff" ? "bg-white/10 text-white" : "text-gray-500 hover:text-gray-300"}`}>
+                                DIFF{preview.has_baseline ? ` (${preview.diff.length})` : ""}
+                            </button>
+                        </div>
+
+                        <div className="flex-1 overflow-auto bg-black/40 rounded-lg p-3 mb-4 min-h-[200px]">
+                            {tab === "config" ? (
+                                <pre className="text-[10px] font-mono text-gray-300 whitespace-pre">{preview.config}</pre>
+                            ) : !preview.has_baseline ? (
+                                <p className="text-[10px] text-gray-500">No successful connection yet, nothing to compare against.</p>
+                            ) : preview.diff.length === 0 ? (
+                                <p className="text-[10px] text-gray-500">Identical to the config of the last successful connection.</p>
+                            ) : (
+                                preview.diff.map((c, i) => (
+                                    <div key={i} className="text-[10px] font-mono mb-1.5 break-all">
+                                        <span className={`${kindColor(c.kind)} font-bold`}>{c.kind === "added" ? "+" : c.kind === "removed" ? "-" : "~"} {c.path}</span>
+                                        {c.kind !== "added" && <div className="text-red-400/70 pl-3">{formatValue(c.old)}</div>}
+                                        {c.kind !== "removed" && <div className="text-green-400/70 pl-3">{formatValue(c.new)}</div>}
+                                    </div>
+                                ))
+                            )}
+                        </div>
+                    </>
+                )}
+
+                <div className="flex gap-2">
+                    {preview?.config && (
+                        <button
+                            onClick={() => ClipboardSetText(preview.config)}
+                            className="flex-1 py-2.5 rounded-xl text-[10px] font-bold text-gray-400 hover:text-white bg-white/5 hover:bg-white/10 border border-transparent transition-all"
+                        >
+                            COPY JSON
+                        </button>
+                    )}
+                    <button
+                        onClick={onClose}
+                        className="flex-1 py-2.5 rounded-xl text-[10px] font-bold text-gray-400 hover:text-white bg-white/5 hover:bg-white/10 border border-transparent transition-all"
+                    >
+                        CLOSE
+                    </button>
+                </div>
+            </div>
+        </div>
+    );
+};
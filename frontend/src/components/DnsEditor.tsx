@@ -0,0 +1,134 @@
+import React, { useState, useEffect } from 'react';
+import { main } from "../../wailsjs/go/models";
+import { ValidateDNS } from "../../wailsjs/go/main/App";
+import { CustomSelect } from './CustomSelect';
+import { ListInput } from './ListInput';
+
+interface Props {
+    dns: main.DNSSettings;
+    onChange: (dns: main.DNSSettings) => void;
+}
+
+const inputClass = "bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white font-mono outline-none focus:border-purple-500/50 min-w-0";
+const labelClass = "text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1";
+
+const typeOptions = [
+    { value: "udp", label: "UDP" },
+    { value: "tcp", label: "TCP" },
+    { value: "tls", label: "DoT" },
+    { value: "https", label: "DoH" },
+    { value: "quic", label: "DoQ" },
+];
+
+const detourOptions = [
+    { value: "proxy", label: "Proxy" },
+    { value: "direct", label: "Direct" },
+];
+
+const strategyOptions = [
+    { value: "", label: "Automatic" },
+    { value: "prefer_ipv4", label: "Prefer IPv4" },
+    { value: "prefer_ipv6", label: "Prefer IPv6" },
+    { value: "ipv4_only", label: "IPv4 Only" },
+    { value: "ipv6_only", label: "IPv6 Only" },
+];
+
+export const DnsEditor: React.FC<Props> = ({ dns, onChange }) => {
+    const [error, setError] = useState<string | null>(null);
+    const servers = dns.servers || [];
+    const rules = dns.rules || [];
+
+    useEffect(() => {
+        ValidateDNS(dns).then(res => setError(res === "OK" ? null : res));
+    }, [dns]);
+
+    const update = (changes: Partial<main.DNSSettings>) => onChange(new main.DNSSettings({ ...dns, ...changes }));
+    const updateServer = (i: number, changes: Partial<main.DNSServer>) =>
+        update({ servers: servers.map((s, j) => j === i ? new main.DNSServer({ ...s, ...changes }) : s) });
+    const updateFakeIP = (changes: Partial<main.FakeIPSettings>) => update({ fake_ip: new main.FakeIPSettings({ ...dns.fake_ip, ...changes }) });
+    const updateRule = (i: number, changes: Partial<main.DNSRule>) =>
+        update({ rules: rules.map((r, j) => j === i ? new main.DNSRule({ ...r, ...changes }) : r) });
+
+    const serverOptions = [...servers.map(s => ({ value: s.tag, label: s.tag })), { value: "local_dns", label: "System" }];
+
+    return (
+        <div className="space-y-4">
+            <div>
+                <label className={labelClass}>Servers</label>
+                <div className="space-y-2 mt-1.5">
+                    {servers.map((s, i) => (
+                        <div key={i} className="flex gap-2 items-center">
+                            <input value={s.tag} onChange={(e) => updateServer(i, { tag: e.target.value })} placeholder="tag" className={`${inputClass} w-24`} />
+                            <CustomSelect value={s.type} onChange={(v) => updateServer(i, { type: v })} options={typeOptions} className="w-20" />
+                            <input value={s.address} onChange={(e) => updateServer(i, { address: e.target.value })} placeholder="1.1.1.1 or dns.google/dns-query" className={`${inputClass} flex-1`} />
+                            <CustomSelect value={s.detour === "proxy" ? "proxy" : "direct"} onChange={(v) => updateServer(i, { detour: v })} options={detourOptions} className="w-24" />
+                            <button onClick={() => update({ servers: servers.filter((_, j) => j !== i) })} disabled={servers.length === 1} className="text-gray-600 hover:text-red-400 disabled:opacity-30 text-xs px-1">✕</button>
+                        </div>
+                    ))}
+                </div>
+                <button onClick={() => update({ servers: [...servers, new main.DNSServer({ tag: `dns${servers.length + 1}`, type: "https", address: "", detour: "proxy" })] })} className="mt-2 text-[10px] font-bold text-purple-400 hover:text-purple-300">+ ADD SERVER</button>
+            </div>
+
+            <div>
+                <label className={labelClass}>Domain Rules</label>
+                <div className="space-y-2 mt-1.5">
+                    {rules.map((r, i) => (
+                        <div key={i} className="flex gap-2 items-center">
+                            <ListInput
+                                value={r.domains || []}
+                                onChange={(domains) => updateRule(i, { domains })}
+                                placeholder="corp.example.com, intranet"
+                                className={`${inputClass} flex-1`}
+                            />
+                            <CustomSelect value={r.server} onChange={(v) => updateRule(i, { server: v })} options={serverOptions} className="w-28" />
+                            <button onClick={() => update({ rules: rules.filter((_, j) => j !== i) })} className="text-gray-600 hover:text-red-400 text-xs px-1">✕</button>
+                        </div>
+                    ))}
+                </div>
+                <button onClick={() => update({ rules: [...rules, new main.DNSRule({ domains: [], server: servers[0]?.tag || "local_dns" })] })} className="mt-2 text-[10px] font-bold text-purple-400 hover:text-purple-300">+ ADD RULE</button>
+            </div>
+
+            <div className="grid grid-cols-3 gap-3">
+                <div className="flex flex-col">
+                    <label className={labelClass}>Default Server</label>
+                    <CustomSelect value={dns.final || ""} onChange={(v) => update({ final: v })} options={[{ value: "", label: "First" }, ...serverOptions]} className="w-full" />
+                </div>
+                <div className="flex flex-col">
+                    <label className={labelClass}>Strategy</label>
+                    <CustomSelect value={dns.strategy || ""} onChange={(v) => update({ strategy: v })} options={strategyOptions} className="w-full" />
+                </div>
+                <div className="flex flex-col">
+                    <label className={labelClass}>Cache Size</label>
+                    <input type="number" value={dns.cache_capacity || ""} onChange={(e) => update({ cache_capacity: parseInt(e.target.value) || 0 })} placeholder="1024" className={`${inputClass} [&::-webkit-inner-spin-button]:appearance-none`} />
+                </div>
+            </div>
+
+            <div className="flex gap-4 text-[11px] text-gray-400">
+                <label className="flex items-center gap-2 cursor-pointer"><input type="checkbox" checked={!!dns.disable_cache} onChange={() => update({ disable_cache: !dns.disable_cache })} />No cache</label>
+                <label className="flex items-center gap-2 cursor-pointer"><input type="checkbox" checked={!!dns.disable_expire} onChange={() => update({ disable_expire: !dns.disable_expire })} />Never expire</label>
+                <label className="flex items-center gap-2 cursor-pointer"><input type="checkbox" checked={!!dns.independent_cache} onChange={() => update({ independent_cache: !dns.independent_cache })} />Cache per server</label>
+            </div>
+
+            <div className="pt-2 border-t border-white/5">
+                <label className="flex items-center gap-2 cursor-pointer text-[11px] text-gray-400">
+                    <input type="checkbox" checked={!!dns.fake_ip?.enabled} onChange={() => updateFakeIP({ enabled: !dns.fake_ip?.enabled })} />
+                    FakeIP <span className="text-gray-600">(TUN mode only: proxied domains resolve to fake addresses)</span>
+                </label>
+                {dns.fake_ip?.enabled && (
+                    <div className="grid grid-cols-2 gap-3 mt-3">
+                        <div className="flex flex-col">
+                            <label className={labelClass}>IPv4 Range</label>
+                            <input value={dns.fake_ip.inet4_range || ""} onChange={(e) => updateFakeIP({ inet4_range: e.target.value })} placeholder="198.18.0.0/15" className={inputClass} />
+                        </div>
+                        <div className="flex flex-col">
+                            <label className={labelClass}>IPv6 Range</label>
+                            <input value={dns.fake_ip.inet6_range || ""} onChange={(e) => updateFakeIP({ inet6_range: e.target.value })} placeholder="fc00::/18" className={inputClass} />
+                        </div>
+                    </div>
+                )}
+            </div>
+
+            {error && <div className="text-[10px] text-red-400">{error}</div>}
+        </div>
+    );
+};
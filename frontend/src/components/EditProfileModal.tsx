@@ -1,13 +1,19 @@
 import React, { useState, useEffect, useRef } from 'react';
 import { VlessConfig, parseVless, buildVless } from '../utils/vless';
 import { CustomSelect } from './CustomSelect';
+import { main } from '../../wailsjs/go/models';
 
 interface Props {
     isOpen: boolean;
     initialName: string;
     initialKey: string;
+    initialOptions?: main.ProfileOptions;
+    initialTags?: string[];
+    initialDetour?: string;
+    profileId?: string;
+    profiles?: main.Profile[];
     onClose: () => void;
-    onSave: (name: string, key: string) => void;
+    onSave: (name: string, key: string, options: main.ProfileOptions, tags: string[], detour: string) => void;
 }
 
 const Field = ({ label, value, onChange, placeholder = "", className = "" }: any) => (
@@ -30,8 +36,32 @@ const SelectField = ({ label, value, onChange, options }: any) => (
     </div>
 );
 
-export const EditProfileModal: React.FC<Props> = ({ isOpen, initialName, initialKey, onClose, onSave }) => {
+const muxValue = (o?: main.ProfileOptions) => !o?.multiplex ? "" : o.multiplex.enabled ? (o.multiplex.protocol || "h2mux") : "off";
+const fragmentValue = (o?: main.ProfileOptions) => {
+    const f = o?.fragment;
+    if (!f) return "";
+    if (f.fragment && f.record_fragment) return "both";
+    return f.fragment ? "tcp" : f.record_fragment ? "record" : "off";
+};
+
+// A profile can detour through any other profile whose own chain does not
+// lead back to it.
+const detourCandidates = (profiles: main.Profile[], id?: string) => profiles.filter(p => {
+    const seen = new Set<string>();
+    for (let cur: main.Profile | undefined = p; cur && !seen.has(cur.id); cur = profiles.find(x => x.id === cur!.detour_id)) {
+        if (cur.id === id) return false;
+        seen.add(cur.id);
+    }
+    return true;
+});
+
+export const EditProfileModal: React.FC<Props> = ({ isOpen, initialName, initialKey, initialOptions, initialTags, initialDetour, profileId, profiles = [], onClose, onSave }) => {
     const [name, setName] = useState(initialName);
+    const [tags, setTags] = useState((initialTags || []).join(", "));
+    const [detour, setDetour] = useState(initialDetour || "");
+    const [mux, setMux] = useState(muxValue(initialOptions));
+    const [muxConns, setMuxConns] = useState(String(initialOptions?.multiplex?.max_connections || ""));
+    const [fragment, setFragment] = useState(fragmentValue(initialOptions));
     const [config, setConfig] = useState<VlessConfig | null>(null);
     const [rawKey, setRawKey] = useState(initialKey);
     const [mode, setMode] = useState<"visual" | "raw">("visual");
@@ -52,7 +82,9 @@ export const EditProfileModal: React.FC<Props> = ({ isOpen, initialName, initial
         { value: "tcp", label: "TCP" },
         { value: "ws", label: "WebSocket" },
         { value: "grpc", label: "gRPC" },
-        { value: "http", label: "HTTP" }
+        { value: "http", label: "HTTP" },
+        { value: "httpupgrade", label: "HTTPUpgrade" },
+        { value: "quic", label: "QUIC" }
     ];
 
     const flowOptions = [
@@ -60,6 +92,22 @@ export const EditProfileModal: React.FC<Props> = ({ isOpen, initialName, initial
         { value: "xtls-rprx-vision", label: "xtls-rprx-vision" },
     ];
 
+    const muxOptions = [
+        { value: "", label: "From link" },
+        { value: "off", label: "Off" },
+        { value: "h2mux", label: "h2mux" },
+        { value: "smux", label: "smux" },
+        { value: "yamux", label: "yamux" }
+    ];
+
+    const fragmentOptions = [
+        { value: "", label: "From link" },
+        { value: "off", label: "Off" },
+        { value: "tcp", label: "TCP segments" },
+        { value: "record", label: "TLS records" },
+        { value: "both", label: "Both" }
+    ];
+
     const fpOptions = [
         { value: "chrome", label: "Chrome" },
         { value: "firefox", label: "Firefox" },
@@ -79,7 +127,12 @@ export const EditProfileModal: React.FC<Props> = ({ isOpen, initialName, initial
             setTimeout(() => setIsVisible(true), 50);
 
             setName(initialName);
+            setTags((initialTags || []).join(", "));
+            setDetour(initialDetour || "");
             setRawKey(initialKey);
+            setMux(muxValue(initialOptions));
+            setMuxConns(String(initialOptions?.multiplex?.max_connections || ""));
+            setFragment(fragmentValue(initialOptions));
             const parsed = parseVless(initialKey);
             if (parsed) {
                 setConfig(parsed);
@@ -92,13 +145,13 @@ export const EditProfileModal: React.FC<Props> = ({ isOpen, initialName, initial
             const timer = setTimeout(() => setShouldRender(false), 300);
             return () => clearTimeout(timer);
         }
-    }, [isOpen, initialName, initialKey]);
+    }, [isOpen, initialName, initialKey, initialOptions, initialTags, initialDetour]);
 
     useEffect(() => {
         if (contentRef.current) {
             setContentHeight(contentRef.current.scrollHeight + 4);
         }
-    }, [mode, config, isVisible]);
+    }, [mode, config, isVisible, mux]);
 
     useEffect(() => {
         const handleKeyDown = (e: KeyboardEvent) => {
@@ -108,12 +161,31 @@ export const EditProfileModal: React.FC<Props> = ({ isOpen, initialName, initial
         return () => window.removeEventListener('keydown', handleKeyDown);
     }, [isOpen, onClose]);
 
+    const buildOptions = (): main.ProfileOptions => {
+        const options = new main.ProfileOptions();
+        if (mux) {
+            options.multiplex = new main.MultiplexOptions({
+                enabled: mux !== "off",
+                protocol: mux === "off" ? "" : mux,
+                max_connections: parseInt(muxConns) || 0,
+            });
+        }
+        if (fragment) {
+            options.fragment = new main.FragmentOptions({
+                fragment: fragment === "tcp" || fragment === "both",
+                record_fragment: fragment === "record" || fragment === "both",
+            });
+        }
+        return options;
+    };
+
     const handleSave = () => {
+        const tagList = tags.split(",").map(t => t.trim()).filter(Boolean);
         if (mode === "visual" && config) {
             const newLink = buildVless({...config, name: name});
-            onSave(name, newLink);
+            onSave(name, newLink, buildOptions(), tagList, detour);
         } else {
-            onSave(name, rawKey);
+            onSave(name, rawKey, buildOptions(), tagList, detour);
         }
     };
 
@@ -157,8 +229,18 @@ export const EditProfileModal: React.FC<Props> = ({ isOpen, initialName, initial
                     }}
                 >
                     <div ref={contentRef}>
-                        <div className="mb-4">
+                        <div className="grid grid-cols-2 gap-3 mb-4">
                             <Field label="Display Name" value={name} onChange={setName} />
+                            <Field label="Tags" value={tags} onChange={setTags} placeholder="eu, fast" />
+                        </div>
+
+                        <div className="mb-4">
+                            <SelectField
+                                label="Connect Through (Chain)"
+                                value={detour}
+                                onChange={setDetour}
+                                options={[{ value: "", label: "Direct" }, ...detourCandidates(profiles, profileId).map(p => ({ value: p.id, label: p.name }))]}
+                            />
                         </div>
 
                         <div key={mode} className="animate-[fadeIn_0.3s_ease-out]">
@@ -232,6 +314,17 @@ export const EditProfileModal: React.FC<Props> = ({ isOpen, initialName, initial
                                 </div>
                             )}
                         </div>
+
+                        <div className="bg-white/5 rounded-xl p-4 border border-white/5 space-y-3 mt-4">
+                            <div className="text-[10px] font-bold text-gray-400 mb-2 uppercase tracking-widest">Anti-DPI</div>
+                            <div className="grid grid-cols-2 gap-3">
+                                <SelectField label="Multiplex" value={mux} onChange={setMux} options={muxOptions} />
+                                <SelectField label="TLS Fragment" value={fragment} onChange={setFragment} options={fragmentOptions} />
+                                {mux && mux !== "off" && (
+                                    <Field label="Max Connections" value={muxConns} onChange={setMuxConns} placeholder="4" />
+                                )}
+                            </div>
+                        </div>
                     </div>
                 </div>
 
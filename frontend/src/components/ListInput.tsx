@@ -0,0 +1,33 @@
+import React, { useState, useEffect } from 'react';
+
+interface Props {
+    value: string[];
+    onChange: (value: string[]) => void;
+    placeholder?: string;
+    className?: string;
+}
+
+// ListInput edits a list as comma separated text and commits it on blur, so
+// typing a comma does not get normalized away.
+export const ListInput: React.FC<Props> = ({ value, onChange, placeholder = "", className = "" }) => {
+    const joined = (value || []).join(", ");
+    const [text, setText] = useState(joined);
+
+    useEffect(() => { setText(joined); }, [joined]);
+
+    const commit = () => {
+        const list = text.split(",").map(v => v.trim()).filter(Boolean);
+        if (list.join(", ") !== joined) onChange(list);
+        else setText(joined);
+    };
+
+    return (
+        <input
+            value={text}
+            onChange={(e) => setText(e.target.value)}
+            onBlur={commit}
+            placeholder={placeholder}
+            className={className}
+        />
+    );
+};
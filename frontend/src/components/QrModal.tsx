@@ -0,0 +1,33 @@
+import React from 'react';
+
+interface Props {
+    isOpen: boolean;
+    onClose: () => void;
+    title: string;
+    image: string;
+}
+
+export const QrModal: React.FC<Props> = ({ isOpen, onClose, title, image }) => {
+    if (!isOpen) return null;
+
+    return (
+        <div className="fixed inset-0 z-[100] flex items-center justify-center bg-black/80 backdrop-blur-md animate-[fadeIn_0.2s_ease-out]" onClick={onClose}>
+            <div className="w-80 bg-[#0f0f13] p-6 rounded-2xl border border-white/10 shadow-[0_0_50px_-10px_rgba(0,0,0,0.8)] animate-[scaleIn_0.2s_ease-out] flex flex-col items-center text-center" onClick={(e) => e.stopPropagation()}>
+                <h3 className="text-sm font-bold text-white mb-4 truncate max-w-full">{title}</h3>
+
+                {image.startsWith("data:") ? (
+                    <img src={image} alt="QR code" className="w-64 h-64 rounded-xl bg-white p-2 mb-6" />
+                ) : (
+                    <p className="text-[11px] text-red-400 mb-6 leading-relaxed px-2">{image}</p>
+                )}
+
+                <button
+                    onClick={onClose}
+                    className="w-full py-2.5 rounded-xl text-[10px] font-bold text-gray-400 hover:text-white bg-white/5 hover:bg-white/10 border border-transparent transition-all"
+                >
+                    CLOSE
+                </button>
+            </div>
+        </div>
+    );
+};
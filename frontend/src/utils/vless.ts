@@ -12,13 +12,20 @@ export interface VlessConfig {
     fp: string;
     path: string;
     host: string;
+    extra: string;
 }
 
+// Parameters edited through the form; everything else is kept in `extra`
+// so that alpn, ech, early data etc. survive a visual edit.
+const knownParams = ["security", "type", "flow", "sni", "pbk", "sid", "fp", "path", "host"];
+
 export const parseVless = (link: string): VlessConfig | null => {
     try {
         if (!link.startsWith("vless://")) return null;
         const url = new URL(link);
         const params = url.searchParams;
+        const extra = new URLSearchParams();
+        params.forEach((v, k) => { if (!knownParams.includes(k)) extra.append(k, v); });
 
         return {
             uuid: url.username,
@@ -35,6 +42,7 @@ export const parseVless = (link: string): VlessConfig | null => {
             fp: params.get("fp") || "",
             path: params.get("path") || "",
             host: params.get("host") || "",
+            extra: extra.toString(),
         };
     } catch (e) {
         console.error("VLESS Parse Error", e);
@@ -55,6 +63,7 @@ export const buildVless = (c: VlessConfig): string => {
     if (c.fp) params.append("fp", c.fp);
     if (c.path) params.append("path", c.path);
     if (c.host) params.append("host", c.host);
+    new URLSearchParams(c.extra).forEach((v, k) => params.append(k, v));
 
     link += params.toString();
     if (c.name) link += `#${encodeURIComponent(c.name)}`;
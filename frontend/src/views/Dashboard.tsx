@@ -1,8 +1,11 @@
 import React, { useState, useRef, useEffect } from 'react';
-import { AddProfile, CreateSubscription, GetSubscriptions, UpdateSubscription, DeleteSubscription, UpdateProfile } from "../../wailsjs/go/main/App";
+import { AddProfile, ImportWireGuard, CreateSubscription, GetSubscriptions, UpdateSubscription, DeleteSubscription, UpdateProfile, SetProfileOptions, SetProfileTags, SetProfileDetour, GetShareLink, ExportProfiles, GetProfileQR, ImportQR, PreviewConfig } from "../../wailsjs/go/main/App";
+import { ClipboardSetText } from "../../wailsjs/runtime/runtime";
 import { main } from "../../wailsjs/go/models";
 import { ConfirmationModal } from '../components/ConfirmationModal';
 import { EditProfileModal } from '../components/EditProfileModal';
+import { QrModal } from '../components/QrModal';
+import { ConfigPreviewModal } from '../components/ConfigPreviewModal';
 
 type UIProfile = main.Profile & { latency?: number };
 interface TrafficData { up: number; down: number; }
@@ -26,13 +29,15 @@ export const Dashboard: React.FC<Props> = ({
                                                onToggle, onSelect, onDelete, onPing, onRefreshProfiles
                                            }) => {
     const [isAdding, setIsAdding] = useState(false);
-    const [addType, setAddType] = useState<"key" | "sub">("key");
+    const [addType, setAddType] = useState<"key" | "sub" | "wg" | "qr" | "json">("key");
     const [inputVal, setInputVal] = useState("");
     const [isProcessing, setIsProcessing] = useState(false);
     const [subscriptions, setSubscriptions] = useState<main.Subscription[]>([]);
     const [updatingSubId, setUpdatingSubId] = useState<string | null>(null);
     const [subToDelete, setSubToDelete] = useState<string | null>(null);
     const [profileToEdit, setProfileToEdit] = useState<UIProfile | null>(null);
+    const [qrProfile, setQrProfile] = useState<{ name: string; image: string } | null>(null);
+    const [previewProfile, setPreviewProfile] = useState<{ name: string; preview: main.ConfigPreview | null } | null>(null);
 
     const inputRef = useRef<HTMLInputElement>(null);
 
@@ -53,7 +58,10 @@ export const Dashboard: React.FC<Props> = ({
     const handleAdd = async () => {
         if (!inputVal) return;
         setIsProcessing(true);
-        if (addType === "key") { await AddProfile(inputVal); } else { await CreateSubscription(inputVal); await loadSubs(); }
+        if (addType === "key" || addType === "json") { await AddProfile(inputVal); }
+        else if (addType === "wg") { await ImportWireGuard(inputVal); }
+        else if (addType === "qr") { await ImportQR(inputVal); }
+        else { await CreateSubscription(inputVal); await loadSubs(); }
         setInputVal(""); setIsProcessing(false); setIsAdding(false); onRefreshProfiles();
     };
 
@@ -65,14 +73,49 @@ export const Dashboard: React.FC<Props> = ({
         if (subToDelete) { await DeleteSubscription(subToDelete); await loadSubs(); await onRefreshProfiles(); setSubToDelete(null); }
     };
 
-    const handleSaveProfile = async (name: string, key: string) => {
+    const handleSaveProfile = async (name: string, key: string, options: main.ProfileOptions, tags: string[], detour: string) => {
         if (profileToEdit) {
             await UpdateProfile(profileToEdit.id, name, key);
+            await SetProfileOptions(profileToEdit.id, options);
+            await SetProfileTags(profileToEdit.id, tags);
+            await SetProfileDetour(profileToEdit.id, detour);
             onRefreshProfiles();
             setProfileToEdit(null);
         }
     };
 
+    const handleCopyLink = async (e: React.MouseEvent, id: string) => {
+        e.stopPropagation();
+        const link = await GetShareLink(id);
+        if (!link.startsWith("Error")) await ClipboardSetText(link);
+    };
+
+    const handleShowQr = async (e: React.MouseEvent, profile: UIProfile) => {
+        e.stopPropagation();
+        setQrProfile({ name: profile.name, image: await GetProfileQR(profile.id) });
+    };
+
+    const handlePreview = async (e: React.MouseEvent, profile: UIProfile) => {
+        e.stopPropagation();
+        setPreviewProfile({ name: profile.name, preview: null });
+        const preview = await PreviewConfig(profile.id);
+        setPreviewProfile(p => p && { ...p, preview });
+    };
+
+    const handleQrPaste = (e: React.ClipboardEvent) => {
+        const file = Array.from(e.clipboardData.files).find(f => f.type.startsWith("image/"));
+        if (!file) return;
+        e.preventDefault();
+        const reader = new FileReader();
+        reader.onload = () => setInputVal(reader.result as string);
+        reader.readAsDataURL(file);
+    };
+
+    const handleExport = async () => {
+        const body = await ExportProfiles(profiles.map(p => p.id));
+        await ClipboardSetText(body);
+    };
+
     const manualProfiles = profiles.filter(p => !p.subscription_id);
     const getSubProfiles = (subId: string) => profiles.filter(p => p.subscription_id === subId);
 
@@ -119,6 +162,27 @@ export const Dashboard: React.FC<Props> = ({
                 >
                     <svg className="w-3.5 h-3.5" fill="none" viewBox="0 0 24 24" stroke="currentColor"><path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M15.232 5.232l3.536 3.536m-2.036-5.036a2.5 2.5 0 113.536 3.536L6.5 21.036H3v-3.572L16.732 3.732z" /></svg>
                 </button>
+                <button
+                    onClick={(e) => handleShowQr(e, profile)}
+                    className="text-gray-400 hover:text-white p-1.5 rounded-md hover:bg-white/10 transition-colors relative z-20"
+                    title="QR code"
+                >
+                    <svg className="w-3.5 h-3.5" fill="none" viewBox="0 0 24 24" stroke="currentColor"><path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M12 4v1m6 11h2m-6 0h-2v4m0-11v3m0 0h.01M12 12h4.01M16 20h4M4 12h4m12 0h.01M5 8h2a1 1 0 001-1V5a1 1 0 00-1-1H5a1 1 0 00-1 1v2a1 1 0 001 1zm12 0h2a1 1 0 001-1V5a1 1 0 00-1-1h-2a1 1 0 00-1 1v2a1 1 0 001 1zM5 20h2a1 1 0 001-1v-2a1 1 0 00-1-1H5a1 1 0 00-1 1v2a1 1 0 001 1z" /></svg>
+                </button>
+                <button
+                    onClick={(e) => handlePreview(e, profile)}
+                    className="text-gray-400 hover:text-white p-1.5 rounded-md hover:bg-white/10 transition-colors relative z-20"
+                    title="Preview config"
+                >
+                    <svg className="w-3.5 h-3.5" fill="none" viewBox="0 0 24 24" stroke="currentColor"><path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M10 20l4-16m4 4l4 4-4 4M6 16l-4-4 4-4" /></svg>
+                </button>
+                <button
+                    onClick={(e) => handleCopyLink(e, profile.id)}
+                    className="text-gray-400 hover:text-white p-1.5 rounded-md hover:bg-white/10 transition-colors relative z-20"
+                    title="Copy link"
+                >
+                    <svg className="w-3.5 h-3.5" fill="none" viewBox="0 0 24 24" stroke="currentColor"><path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M13.828 10.172a4 4 0 00-5.656 0l-4 4a4 4 0 105.656 5.656l1.102-1.101m-.758-4.899a4 4 0 005.656 0l4-4a4 4 0 00-5.656-5.656l-1.1 1.1" /></svg>
+                </button>
                 <button
                     onClick={(e) => onDelete(e, profile.id)}
                     className="text-gray-400 hover:text-red-400 p-1.5 rounded-md hover:bg-red-500/10 transition-colors relative z-20"
@@ -146,6 +210,7 @@ export const Dashboard: React.FC<Props> = ({
                     <div className="flex justify-between items-center pb-4 border-b border-white/5 mb-5">
                         <span className="text-gray-400 text-[10px] font-bold tracking-widest uppercase">Servers</span>
                         <div className="flex items-center gap-2">
+                            <button onClick={handleExport} title="Copy all as subscription" className="w-6 h-6 rounded flex items-center justify-center transition-all bg-white/5 hover:bg-white/10 text-gray-400 hover:text-white"><svg xmlns="http://www.w3.org/2000/svg" className="h-3.5 w-3.5" fill="none" viewBox="0 0 24 24" stroke="currentColor"><path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M8.684 13.342C8.886 12.938 9 12.482 9 12c0-.482-.114-.938-.316-1.342m0 2.684a3 3 0 110-2.684m0 2.684l6.632 3.316m-6.632-6l6.632-3.316m0 0a3 3 0 105.367-2.684 3 3 0 00-5.367 2.684zm0 9.316a3 3 0 105.368 2.684 3 3 0 00-5.368-2.684z" /></svg></button>
                             <button onClick={onPing} className={`w-6 h-6 rounded flex items-center justify-center transition-all bg-white/5 hover:bg-white/10 text-gray-400 hover:text-white`}><svg xmlns="http://www.w3.org/2000/svg" className={`h-3.5 w-3.5 ${isPinging ? "animate-spin text-purple-400" : ""}`} fill="none" viewBox="0 0 24 24" stroke="currentColor"><path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M4 4v5h.582m15.356 2A8.001 8.001 0 004.582 9m0 0H9m11 11v-5h-.581m0 0a8.003 8.003 0 01-15.357-2m15.357 2H15" /></svg></button>
                             <button onClick={() => setIsAdding(!isAdding)} className={`w-6 h-6 rounded flex items-center justify-center transition-all duration-500 ${isAdding ? "bg-purple-600 text-white rotate-45" : "bg-white/5 hover:bg-white/10 text-gray-400"}`}><svg xmlns="http://www.w3.org/2000/svg" className="h-3 w-3 transition-transform" fill="none" viewBox="0 0 24 24" stroke="currentColor"><path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M12 4v16m8-8H4" /></svg></button>
                         </div>
@@ -161,16 +226,30 @@ export const Dashboard: React.FC<Props> = ({
                                     <div className="flex gap-2 mb-2">
                                         <button onClick={() => setAddType("key")} className={`flex-1 text-[10px] py-1 rounded transition-colors ${addType === "key" ? "bg-white/10 text-white" : "text-gray-500 hover:text-gray-300"}`}>KEY</button>
                                         <button onClick={() => setAddType("sub")} className={`flex-1 text-[10px] py-1 rounded transition-colors ${addType === "sub" ? "bg-white/10 text-white" : "text-gray-500 hover:text-gray-300"}`}>SUB</button>
+                                        <button onClick={() => setAddType("wg")} className={`flex-1 text-[10px] py-1 rounded transition-colors ${addType === "wg" ? "bg-white/10 text-white" : "text-gray-500 hover:text-gray-300"}`}>WG</button>
+                                        <button onClick={() => setAddType("qr")} className={`flex-1 text-[10px] py-1 rounded transition-colors ${addType === "qr" ? "bg-white/10 text-white" : "text-gray-500 hover:text-gray-300"}`}>QR</button>
+                                        <button onClick={() => setAddType("json")} className={`flex-1 text-[10px] py-1 rounded transition-colors ${addType === "json" ? "bg-white/10 text-white" : "text-gray-500 hover:text-gray-300"}`}>JSON</button>
                                     </div>
+                                    {addType === "wg" || addType === "json" ? (
+                                        <textarea
+                                            placeholder={addType === "wg" ? "/path/to/wg0.conf or [Interface]..." : '{"type": "shadowtls", "server": ...}'}
+                                            value={inputVal}
+                                            onChange={(e) => setInputVal(e.target.value)}
+                                            rows={4}
+                                            className="w-full bg-transparent border-b border-white/10 px-1 py-2 text-xs text-white outline-none focus:border-purple-500 mb-3 placeholder:text-gray-700 font-mono resize-none"
+                                        />
+                                    ) : (
                                     <input
                                         ref={inputRef}
                                         type="text"
-                                        placeholder={addType === "key" ? "vless://..." : "https://..."}
+                                        placeholder={addType === "key" ? "vless://, vmess://, trojan://, ss://..." : addType === "qr" ? "/path/to/qr.png or paste image" : "https://..."}
                                         value={inputVal}
                                         onChange={(e) => setInputVal(e.target.value)}
+                                        onPaste={addType === "qr" ? handleQrPaste : undefined}
                                         onKeyDown={(e) => e.key === 'Enter' && handleAdd()}
                                         className="w-full bg-transparent border-b border-white/10 px-1 py-2 text-xs text-white outline-none focus:border-purple-500 mb-3 placeholder:text-gray-700 font-mono"
                                     />
+                                    )}
                                     <button onClick={handleAdd} disabled={isProcessing} className="w-full bg-purple-600 hover:bg-purple-500 disabled:opacity-50 text-white text-[10px] font-bold py-2 rounded-lg transition-colors active:scale-95">
                                         {isProcessing ? "PROCESSING..." : "ADD"}
                                     </button>
@@ -206,10 +285,29 @@ export const Dashboard: React.FC<Props> = ({
                 onConfirm={confirmDeleteSub}
             />
 
+            <QrModal
+                isOpen={!!qrProfile}
+                title={qrProfile?.name || ""}
+                image={qrProfile?.image || ""}
+                onClose={() => setQrProfile(null)}
+            />
+
+            <ConfigPreviewModal
+                isOpen={!!previewProfile}
+                title={previewProfile?.name || ""}
+                preview={previewProfile?.preview || null}
+                onClose={() => setPreviewProfile(null)}
+            />
+
             <EditProfileModal
                 isOpen={!!profileToEdit}
                 initialName={profileToEdit?.name || ""}
                 initialKey={profileToEdit?.key || ""}
+                initialOptions={profileToEdit?.options}
+                initialTags={profileToEdit?.tags}
+                initialDetour={profileToEdit?.detour_id}
+                profileId={profileToEdit?.id}
+                profiles={profiles}
                 onClose={() => setProfileToEdit(null)}
                 onSave={handleSaveProfile}
             />
@@ -1,5 +1,6 @@
 import React, { useState, useEffect } from 'react';
 import { main } from "../../wailsjs/go/models";
+import { ValidateUserRule, GetRuleSetStatus, UpdateRuleSets, GetRegions } from "../../wailsjs/go/main/App";
 import { CustomSelect } from '../components/CustomSelect';
 import { RestartBanner } from '../components/RestartBanner';
 import { ProcessSelectorModal } from '../components/ProcessSelectorModal';
@@ -13,22 +14,62 @@ interface Props {
 }
 
 export const RoutingView: React.FC<Props> = ({ settings, onUpdate, hasChanges, isRunning, onRestart }) => {
-    const [newRule, setNewRule] = useState<main.UserRule>(new main.UserRule({ id: "", type: "domain", value: "", outbound: "direct" }));
-    const [ruDomainsText, setRuDomainsText] = useState("");
+    const [condType, setCondType] = useState("domain");
+    const [condValue, setCondValue] = useState("");
+    const [condInvert, setCondInvert] = useState(false);
+    const [outbound, setOutbound] = useState("direct");
+    const [pending, setPending] = useState<main.RuleCondition[]>([]);
+    const [mode, setMode] = useState("and");
+    const [ruleError, setRuleError] = useState<string | null>(null);
+    const [directDomainsText, setDirectDomainsText] = useState("");
+    const [regions, setRegions] = useState<main.Region[]>([]);
     const [isProcessModalOpen, setIsProcessModalOpen] = useState(false);
+    const [newSetTag, setNewSetTag] = useState("");
+    const [newSetLocation, setNewSetLocation] = useState("");
+    const [setStatus, setSetStatus] = useState<main.RuleSetStatus[]>([]);
+    const [updatingSets, setUpdatingSets] = useState(false);
+    const [updateResult, setUpdateResult] = useState("");
 
     useEffect(() => {
-        if (settings.ru_domains) {
-            setRuDomainsText(settings.ru_domains.join("\n"));
+        if (settings.direct_domains) {
+            setDirectDomainsText(settings.direct_domains.join("\n"));
         }
+        GetRegions().then(setRegions);
     }, []);
 
-    const addRule = () => {
-        if (!newRule.value) return;
-        const ruleToAdd = new main.UserRule({ ...newRule, id: Date.now().toString() });
-        const updatedRules = [...settings.user_rules, ruleToAdd];
-        onUpdate(new main.Settings({ ...settings, user_rules: updatedRules }));
-        setNewRule(new main.UserRule({ id: "", type: "domain", value: "", outbound: "direct" }));
+    // A regex may contain commas, so it is always a single value.
+    const parseValues = (type: string, text: string) =>
+        type === "domain_regex" ? [text.trim()].filter(Boolean) : text.split(",").map(v => v.trim()).filter(Boolean);
+
+    const currentCondition = () => condValue.trim()
+        ? new main.RuleCondition({ type: condType, values: parseValues(condType, condValue), invert: condInvert })
+        : null;
+
+    const resetCondition = () => { setCondValue(""); setCondInvert(false); };
+
+    const addCondition = () => {
+        const c = currentCondition();
+        if (!c) return;
+        setPending([...pending, c]);
+        resetCondition();
+    };
+
+    const addRule = async () => {
+        const c = currentCondition();
+        const conditions = c ? [...pending, c] : pending;
+        if (conditions.length === 0) return;
+
+        const id = Date.now().toString();
+        const ruleToAdd = conditions.length === 1 && !conditions[0].invert
+            ? new main.UserRule({ id, type: conditions[0].type, values: conditions[0].values, outbound })
+            : new main.UserRule({ id, type: "", mode, conditions, outbound });
+
+        const res = await ValidateUserRule(ruleToAdd);
+        if (res !== "OK") { setRuleError(res); return; }
+        setRuleError(null);
+        onUpdate(new main.Settings({ ...settings, user_rules: [...settings.user_rules, ruleToAdd] }));
+        setPending([]);
+        resetCondition();
     };
 
     const deleteRule = (id: string) => {
@@ -36,25 +77,85 @@ export const RoutingView: React.FC<Props> = ({ settings, onUpdate, hasChanges, i
         onUpdate(new main.Settings({ ...settings, user_rules: updatedRules }));
     };
 
-    const saveRuDomains = () => {
-        const domains = ruDomainsText.split("\n").map(s => s.trim()).filter(s => s !== "");
-        onUpdate(new main.Settings({ ...settings, ru_domains: domains }));
+    const selectedRegions = settings.regions || [];
+
+    const toggleRegion = (code: string) => {
+        const updated = selectedRegions.includes(code) ? selectedRegions.filter(c => c !== code) : [...selectedRegions, code];
+        onUpdate(new main.Settings({ ...settings, regions: updated }));
+    };
+
+    const saveDirectDomains = () => {
+        const domains = directDomainsText.split("\n").map(s => s.trim()).filter(s => s !== "");
+        onUpdate(new main.Settings({ ...settings, direct_domains: domains }));
+    };
+
+    const ruleSets = settings.rule_sets || [];
+
+    useEffect(() => {
+        GetRuleSetStatus().then(setSetStatus);
+    }, [settings.rule_sets, settings.regions]);
+
+    const updateRuleSets = async () => {
+        setUpdatingSets(true);
+        setUpdateResult(await UpdateRuleSets());
+        setSetStatus(await GetRuleSetStatus());
+        setUpdatingSets(false);
+    };
+
+    const describeCache = (s: main.RuleSetStatus) => {
+        if (!s.cached) return "not cached";
+        if (s.embedded) return "built-in copy";
+        return "updated " + new Date(s.updated_at * 1000).toLocaleDateString();
+    };
+
+    const addRuleSet = () => {
+        const tag = newSetTag.trim(), location = newSetLocation.trim();
+        if (!tag || !location) return;
+        const remote = /^https?:\/\//.test(location);
+        const rs = new main.RuleSet({ tag, type: remote ? "remote" : "local", ...(remote ? { url: location } : { path: location }) });
+        onUpdate(new main.Settings({ ...settings, rule_sets: [...ruleSets.filter(r => r.tag !== tag), rs] }));
+        setNewSetTag(""); setNewSetLocation("");
+    };
+
+    const deleteRuleSet = (tag: string) => {
+        onUpdate(new main.Settings({ ...settings, rule_sets: ruleSets.filter(r => r.tag !== tag) }));
     };
 
     const getPlaceholder = () => {
-        switch(newRule.type) {
-            case "ip": return "1.1.1.1/32";
+        switch(condType) {
+            case "ip": return "1.1.1.1/32, 10.0.0.0/8";
+            case "source_ip": return "192.168.1.0/24";
+            case "port": return "443, 6881-6889";
+            case "network": return "tcp, udp";
+            case "protocol": return "quic, bittorrent";
             case "process": return "chrome.exe";
+            case "domain_keyword": return "google, tracker";
+            case "domain_regex": return "^cdn\\d+\\.example\\.com$";
+            case "rule_set": return ruleSets.map(r => r.tag).join(", ") || "add a rule-set first";
             default: return "example.com";
         }
     };
 
     const ruleTypes = [
         { value: "domain", label: "Domain" },
+        { value: "domain_full", label: "Exact Domain" },
+        { value: "domain_keyword", label: "Keyword" },
+        { value: "domain_regex", label: "Regex" },
         { value: "ip", label: "IP CIDR" },
-        { value: "process", label: "Process" }
+        { value: "source_ip", label: "Source IP" },
+        { value: "port", label: "Port" },
+        { value: "network", label: "Network" },
+        { value: "protocol", label: "Protocol" },
+        { value: "process", label: "Process" },
+        { value: "rule_set", label: "Rule-set" }
     ];
 
+    const typeLabel = (type: string) => ruleTypes.find(t => t.value === type)?.label || type;
+    const describeCondition = (c: main.RuleCondition) => `${c.invert ? "NOT " : ""}${typeLabel(c.type)}: ${(c.values || []).join(", ")}`;
+    const describeRule = (rule: main.UserRule) => rule.conditions && rule.conditions.length > 0
+        ? rule.conditions.map(describeCondition).join(rule.mode === "or" ? "  OR  " : "  AND  ")
+        : [...(rule.value ? [rule.value] : []), ...(rule.values || [])].join(", ");
+
     return (
         <div className="w-full max-w-4xl animate-[fadeIn_0.3s_ease-out] flex gap-6 h-[520px]">
             
@@ -65,18 +166,31 @@ export const RoutingView: React.FC<Props> = ({ settings, onUpdate, hasChanges, i
                 </div>
 
                 
-                <div className="flex items-center gap-3 mb-6 p-1 z-20 relative">
-                    <CustomSelect value={newRule.type} onChange={(v) => setNewRule(new main.UserRule({...newRule, type: v}))} options={ruleTypes} />
-                    
+                {pending.length > 0 && (
+                    <div className="flex items-center gap-2 mb-2 flex-wrap text-[10px] font-mono animate-slideIn">
+                        <button onClick={() => setMode(mode === "and" ? "or" : "and")} className="px-2 py-1 rounded bg-purple-500/20 text-purple-300 font-bold border border-purple-500/20">{mode === "and" ? "ALL OF" : "ANY OF"}</button>
+                        {pending.map((c, i) => (
+                            <span key={i} className="px-2 py-1 rounded bg-white/5 text-gray-300 border border-white/5">
+                                {describeCondition(c)}
+                                <button onClick={() => setPending(pending.filter((_, j) => j !== i))} className="ml-2 text-gray-600 hover:text-red-400">✕</button>
+                            </span>
+                        ))}
+                    </div>
+                )}
+
+                <div className="flex items-center gap-3 mb-2 p-1 z-20 relative">
+                    <CustomSelect value={condType} onChange={(v) => { setCondType(v); setRuleError(null); }} options={ruleTypes} />
+
                     <div className="flex-1 relative flex items-center">
+                        <button onClick={() => setCondInvert(!condInvert)} title="Match everything except these values" className={`absolute left-1 top-1 bottom-1 px-2 text-[9px] font-bold rounded-md transition-colors ${condInvert ? "bg-red-500/20 text-red-300" : "bg-white/5 text-gray-600 hover:text-gray-300"}`}>NOT</button>
                         <input 
                             type="text" 
                             placeholder={getPlaceholder()} 
-                            value={newRule.value} 
-                            onChange={(e) => setNewRule(new main.UserRule({...newRule, value: e.target.value}))} 
-                            className="w-full h-10 bg-[#0a0a0e] border border-white/10 hover:border-purple-500/50 rounded-lg px-4 text-xs text-white placeholder:text-gray-600 outline-none focus:border-purple-500 focus:bg-[#121216] transition-all font-mono shadow-sm" 
+                            value={condValue} 
+                            onChange={(e) => { setCondValue(e.target.value); setRuleError(null); }} 
+                            className="w-full h-10 bg-[#0a0a0e] border border-white/10 hover:border-purple-500/50 rounded-lg pl-12 pr-4 text-xs text-white placeholder:text-gray-600 outline-none focus:border-purple-500 focus:bg-[#121216] transition-all font-mono shadow-sm" 
                         />
-                        {newRule.type === "process" && (
+                        {condType === "process" && (
                             <button 
                                 onClick={() => setIsProcessModalOpen(true)}
                                 className="absolute right-1 top-1 bottom-1 px-3 bg-white/10 hover:bg-white/20 text-[9px] font-bold text-gray-300 rounded-md transition-colors"
@@ -86,10 +200,13 @@ export const RoutingView: React.FC<Props> = ({ settings, onUpdate, hasChanges, i
                         )}
                     </div>
 
-                    <CustomSelect value={newRule.outbound} onChange={(v) => setNewRule(new main.UserRule({...newRule, outbound: v}))} options={[{ value: "direct", label: "Direct", color: "text-emerald-400" }, { value: "proxy", label: "Proxy", color: "text-purple-400" }, { value: "block", label: "Block", color: "text-red-400" }]} />
-                    <button onClick={addRule} disabled={!newRule.value} className="h-10 w-10 shrink-0 flex items-center justify-center bg-purple-600 hover:bg-purple-500 disabled:opacity-50 text-white rounded-lg transition-all shadow-[0_0_15px_rgba(168,85,247,0.3)] hover:shadow-[0_0_25px_rgba(168,85,247,0.5)] active:scale-95"><svg xmlns="http://www.w3.org/2000/svg" className="h-5 w-5" viewBox="0 0 20 20" fill="currentColor"><path fillRule="evenodd" d="M10 3a1 1 0 011 1v5h5a1 1 0 110 2h-5v5a1 1 0 11-2 0v-5H4a1 1 0 110-2h5V4a1 1 0 011-1z" clipRule="evenodd" /></svg></button>
+                    <button onClick={addCondition} disabled={!condValue.trim()} title="Combine with another condition" className="h-10 px-3 shrink-0 bg-white/5 hover:bg-white/10 disabled:opacity-40 border border-white/10 text-[9px] font-bold text-gray-300 rounded-lg transition-all">+ COND</button>
+                    <CustomSelect value={outbound} onChange={setOutbound} options={[{ value: "direct", label: "Direct", color: "text-emerald-400" }, { value: "proxy", label: "Proxy", color: "text-purple-400" }, { value: "block", label: "Block", color: "text-red-400" }]} />
+                    <button onClick={addRule} disabled={!condValue.trim() && pending.length === 0} className="h-10 w-10 shrink-0 flex items-center justify-center bg-purple-600 hover:bg-purple-500 disabled:opacity-50 text-white rounded-lg transition-all shadow-[0_0_15px_rgba(168,85,247,0.3)] hover:shadow-[0_0_25px_rgba(168,85,247,0.5)] active:scale-95"><svg xmlns="http://www.w3.org/2000/svg" className="h-5 w-5" viewBox="0 0 20 20" fill="currentColor"><path fillRule="evenodd" d="M10 3a1 1 0 011 1v5h5a1 1 0 110 2h-5v5a1 1 0 11-2 0v-5H4a1 1 0 110-2h5V4a1 1 0 011-1z" clipRule="evenodd" /></svg></button>
                 </div>
 
+                <div className="h-4 mb-2 px-1 text-[10px] text-red-400 truncate">{ruleError}</div>
+
                 <div className="grid grid-cols-12 gap-4 px-4 py-2 text-[9px] font-bold text-gray-500 tracking-widest border-b border-white/5 select-none"><div className="col-span-2">ACTION</div><div className="col-span-2">TYPE</div><div className="col-span-7">VALUE</div><div className="col-span-1 text-right">DEL</div></div>
 
                 
@@ -98,8 +215,8 @@ export const RoutingView: React.FC<Props> = ({ settings, onUpdate, hasChanges, i
                         settings.user_rules.map(rule => (
                             <div key={rule.id} className="grid grid-cols-12 gap-4 items-center bg-white/5 hover:bg-white/10 p-3 rounded-lg border border-transparent hover:border-white/5 transition-colors group animate-slideIn">
                                 <div className="col-span-2"><span className={`px-2 py-1 rounded text-[9px] font-bold uppercase tracking-wider ${rule.outbound === "direct" ? "bg-emerald-500/20 text-emerald-400 border border-emerald-500/20" : rule.outbound === "proxy" ? "bg-purple-500/20 text-purple-400 border border-purple-500/20" : "bg-red-500/20 text-red-400 border border-red-500/20"}`}>{rule.outbound}</span></div>
-                                <div className="col-span-2 text-[10px] text-gray-400 uppercase font-bold">{rule.type}</div>
-                                <div className="col-span-7 text-xs text-gray-200 font-mono truncate select-text">{rule.value}</div>
+                                <div className="col-span-2 text-[10px] text-gray-400 uppercase font-bold">{rule.conditions && rule.conditions.length > 0 ? (rule.mode === "or" ? "Any of" : "All of") : typeLabel(rule.type)}</div>
+                                <div className="col-span-7 text-xs text-gray-200 font-mono truncate select-text" title={describeRule(rule)}>{describeRule(rule)}</div>
                                 <div className="col-span-1 text-right"><button onClick={() => deleteRule(rule.id)} className="text-gray-600 hover:text-red-400 transition-colors p-1 rounded hover:bg-white/5"><svg className="w-4 h-4" viewBox="0 0 24 24" stroke="currentColor" fill="none" strokeWidth={2}><path strokeLinecap="round" strokeLinejoin="round" d="M6 18L18 6M6 6l12 12"/></svg></button></div>
                             </div>
                         ))
@@ -111,17 +228,57 @@ export const RoutingView: React.FC<Props> = ({ settings, onUpdate, hasChanges, i
 
             
             <div className="glass w-64 rounded-3xl p-6 border-t border-white/10 flex flex-col">
-                <div className="mb-4"><h2 className="text-sm font-bold text-white tracking-tight">Direct Domains</h2><p className="text-[10px] text-gray-500 mt-1">Direct connection list (RU)</p></div>
+                <div className="mb-3"><h2 className="text-sm font-bold text-white tracking-tight">Regions</h2><p className="text-[10px] text-gray-500 mt-1">Domestic traffic goes direct in smart mode</p></div>
+                <div className="flex flex-wrap gap-1 mb-2">
+                    {regions.map(r => (
+                        <button
+                            key={r.code}
+                            onClick={() => toggleRegion(r.code)}
+                            title={`${r.name}: ${r.domains.join(" ")}${r.dns ? `, DNS ${r.dns}` : ""}`}
+                            className={`px-2 py-1 rounded-md text-[10px] font-bold uppercase border transition-all ${selectedRegions.includes(r.code) ? "bg-purple-500/20 border-purple-500/40 text-purple-200" : "bg-white/5 border-white/10 text-gray-500 hover:text-gray-300"}`}
+                        >{r.code}</button>
+                    ))}
+                </div>
+                <label className="flex items-center gap-2 cursor-pointer text-[10px] text-gray-400 mb-4" title="Resolve region domains with a public resolver inside the country instead of the system one">
+                    <input type="checkbox" checked={!!settings.region_dns} onChange={() => onUpdate(new main.Settings({ ...settings, region_dns: !settings.region_dns }))} />Domestic DNS
+                </label>
+
+                <div className="mb-2"><h2 className="text-sm font-bold text-white tracking-tight">Direct Domains</h2><p className="text-[10px] text-gray-500 mt-1">Extra domains that bypass the proxy</p></div>
                 <div className="flex-1 relative mb-4">
-                    <textarea value={ruDomainsText} onChange={(e) => setRuDomainsText(e.target.value)} className="w-full h-full bg-[#0a0a0e] border border-white/10 rounded-xl p-3 text-[10px] font-mono text-gray-300 outline-none focus:border-purple-500/50 resize-none scrollbar-hide leading-relaxed" placeholder=".ru&#10;yandex.ru&#10;..." />
+                    <textarea value={directDomainsText} onChange={(e) => setDirectDomainsText(e.target.value)} className="w-full h-full bg-[#0a0a0e] border border-white/10 rounded-xl p-3 text-[10px] font-mono text-gray-300 outline-none focus:border-purple-500/50 resize-none scrollbar-hide leading-relaxed" placeholder="yandex.net&#10;corp.example.com&#10;..." />
+                </div>
+                <button onClick={saveDirectDomains} className="w-full bg-white/5 hover:bg-white/10 border border-white/10 text-gray-300 text-xs font-bold py-2 rounded-lg transition-all active:scale-95">SAVE LIST</button>
+
+                <div className="mt-5 mb-2"><h2 className="text-sm font-bold text-white tracking-tight">Rule-sets</h2><p className="text-[10px] text-gray-500 mt-1">.srs or .json, by URL or local path</p></div>
+                <div className="space-y-1 max-h-24 overflow-y-auto scrollbar-hide mb-2">
+                    {ruleSets.map(rs => (
+                        <div key={rs.tag} className="flex items-center justify-between bg-white/5 px-2 py-1 rounded text-[10px] font-mono" title={rs.url || rs.path}>
+                            <span className="text-gray-200 truncate">{rs.tag} <span className="text-gray-600">{rs.type}</span></span>
+                            <button onClick={() => deleteRuleSet(rs.tag)} className="text-gray-600 hover:text-red-400 ml-2">✕</button>
+                        </div>
+                    ))}
+                </div>
+                <div className="space-y-0.5 mb-2">
+                    {setStatus.map(s => (
+                        <div key={s.tag} className="flex justify-between text-[9px] font-mono" title={s.url}>
+                            <span className="text-gray-500 truncate">{s.tag}</span>
+                            <span className={s.cached ? "text-gray-500" : "text-yellow-500/80"}>{describeCache(s)}</span>
+                        </div>
+                    ))}
+                </div>
+                <button onClick={updateRuleSets} disabled={updatingSets} className="w-full mb-1 bg-white/5 hover:bg-white/10 disabled:opacity-40 border border-white/10 text-gray-300 text-[10px] font-bold py-1.5 rounded-lg">{updatingSets ? "UPDATING…" : "UPDATE RULE-SETS"}</button>
+                {updateResult && <div className={`text-[9px] mb-2 ${updateResult.startsWith("Error") ? "text-red-400" : "text-gray-500"}`}>{updateResult}</div>}
+                <div className="flex gap-1">
+                    <input value={newSetTag} onChange={(e) => setNewSetTag(e.target.value)} placeholder="tag" className="w-16 bg-[#0a0a0e] border border-white/10 rounded-lg px-2 py-1.5 text-[10px] font-mono text-gray-300 outline-none focus:border-purple-500/50" />
+                    <input value={newSetLocation} onChange={(e) => setNewSetLocation(e.target.value)} placeholder="https://…/ads.srs" className="flex-1 min-w-0 bg-[#0a0a0e] border border-white/10 rounded-lg px-2 py-1.5 text-[10px] font-mono text-gray-300 outline-none focus:border-purple-500/50" />
+                    <button onClick={addRuleSet} disabled={!newSetTag.trim() || !newSetLocation.trim()} className="px-2 bg-white/5 hover:bg-white/10 disabled:opacity-40 border border-white/10 text-gray-300 text-xs font-bold rounded-lg">+</button>
                 </div>
-                <button onClick={saveRuDomains} className="w-full bg-white/5 hover:bg-white/10 border border-white/10 text-gray-300 text-xs font-bold py-2 rounded-lg transition-all active:scale-95">SAVE LIST</button>
             </div>
 
             <ProcessSelectorModal 
                 isOpen={isProcessModalOpen}
                 onClose={() => setIsProcessModalOpen(false)}
-                onSelect={(name) => setNewRule(new main.UserRule({...newRule, value: name}))}
+                onSelect={(name) => setCondValue(condValue.trim() ? `${condValue}, ${name}` : name)}
             />
         </div>
     );
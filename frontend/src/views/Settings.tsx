@@ -1,6 +1,9 @@
-import React from 'react';
+import React, { useState, useEffect } from 'react';
 import { main } from "../../wailsjs/go/models";
+import { ValidateConfigOverlay, GetProfiles, GetSubscriptions } from "../../wailsjs/go/main/App";
 import { RestartBanner } from '../components/RestartBanner';
+import { DnsEditor } from '../components/DnsEditor';
+import { ListInput } from '../components/ListInput';
 
 interface Props {
     settings: main.Settings;
@@ -18,6 +21,40 @@ export const SettingsView: React.FC<Props> = ({ settings, onUpdate, hasChanges,
     };
 
     const isProxy = settings.run_mode === "proxy";
+    const ipv6 = settings.ipv6 || "off";
+
+    const tun = settings.tun || new main.TunSettings();
+    const strictRoute = tun.strict_route !== false;
+    const updateTun = (changes: Partial<main.TunSettings>) => update({ tun: new main.TunSettings({ ...tun, ...changes }) });
+    const parseUIDs = (list: string[]) => list.map(v => parseInt(v)).filter(v => !isNaN(v));
+
+    const isFailover = settings.connect_mode === "failover";
+    const failover = settings.failover || new main.FailoverGroup();
+    const [profiles, setProfiles] = useState<main.Profile[]>([]);
+    const [subscriptions, setSubscriptions] = useState<main.Subscription[]>([]);
+
+    useEffect(() => {
+        if (!isFailover) return;
+        GetProfiles().then(p => setProfiles(p || []));
+        GetSubscriptions().then(s => setSubscriptions(s || []));
+    }, [isFailover]);
+
+    const updateFailover = (changes: Partial<main.FailoverGroup>) => update({ failover: new main.FailoverGroup({ ...failover, ...changes }) });
+    const toggleMember = (id: string) => {
+        const ids = failover.profile_ids || [];
+        updateFailover({ profile_ids: ids.includes(id) ? ids.filter(x => x !== id) : [...ids, id] });
+    };
+
+    const [overlay, setOverlay] = useState(settings.config_overlay || "");
+    const [overlayError, setOverlayError] = useState<string | null>(null);
+
+    useEffect(() => { setOverlay(settings.config_overlay || ""); }, [settings.config_overlay]);
+
+    const saveOverlay = async () => {
+        const res = await ValidateConfigOverlay(overlay);
+        setOverlayError(res === "OK" ? null : res);
+        if (overlay !== (settings.config_overlay || "")) update({ config_overlay: overlay });
+    };
 
     return (
         <div className="w-full max-w-2xl animate-[fadeIn_0.3s_ease-out]">
@@ -50,9 +87,120 @@ export const SettingsView: React.FC<Props> = ({ settings, onUpdate, hasChanges,
                             </div>
                         </div>
                     </div>
+
+                    <div className={`grid transition-all duration-500 ease-[cubic-bezier(0.4,0,0.2,1)] ${!isProxy ? "grid-rows-[1fr] opacity-100 mt-4" : "grid-rows-[0fr] opacity-0 mt-0"}`}>
+                        <div className="overflow-hidden min-h-0">
+                            <div className="bg-white/5 p-4 rounded-xl border border-white/5 space-y-3">
+                                <div className="grid grid-cols-3 gap-3">
+                                    <div className="flex flex-col">
+                                        <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">MTU</label>
+                                        <input type="number" value={tun.mtu || ""} onChange={(e) => updateTun({ mtu: parseInt(e.target.value) || 0 })} placeholder="9000" className="bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white font-mono outline-none focus:border-emerald-500/50 [&::-webkit-inner-spin-button]:appearance-none" />
+                                    </div>
+                                    <div className="flex flex-col">
+                                        <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">Stack</label>
+                                        <select value={tun.stack || "system"} onChange={(e) => updateTun({ stack: e.target.value })} className="bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white font-mono outline-none focus:border-emerald-500/50">
+                                            <option value="system">System</option>
+                                            <option value="gvisor">gVisor</option>
+                                            <option value="mixed">Mixed</option>
+                                        </select>
+                                    </div>
+                                    <div className="flex flex-col">
+                                        <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">Interface</label>
+                                        <input value={tun.interface_name || ""} onChange={(e) => updateTun({ interface_name: e.target.value })} placeholder="auto" className="bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white font-mono outline-none focus:border-emerald-500/50" />
+                                    </div>
+                                </div>
+                                <div className="flex flex-col">
+                                    <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">Excluded Addresses</label>
+                                    <ListInput value={tun.route_exclude_address || []} onChange={(v) => updateTun({ route_exclude_address: v })} placeholder="192.168.100.0/24, fd00::/64" className="bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white font-mono outline-none focus:border-emerald-500/50" />
+                                </div>
+                                <div className="grid grid-cols-2 gap-3">
+                                    <div className="flex flex-col">
+                                        <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">Only UIDs (Linux)</label>
+                                        <ListInput value={(tun.include_uid || []).map(String)} onChange={(v) => updateTun({ include_uid: parseUIDs(v) })} placeholder="1000" className="bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white font-mono outline-none focus:border-emerald-500/50" />
+                                    </div>
+                                    <div className="flex flex-col">
+                                        <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">Skip UIDs (Linux)</label>
+                                        <ListInput value={(tun.exclude_uid || []).map(String)} onChange={(v) => updateTun({ exclude_uid: parseUIDs(v) })} placeholder="0" className="bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white font-mono outline-none focus:border-emerald-500/50" />
+                                    </div>
+                                </div>
+                                <label className="flex items-center gap-2 cursor-pointer text-[11px] text-gray-400">
+                                    <input type="checkbox" checked={strictRoute} onChange={() => updateTun({ strict_route: !strictRoute })} />
+                                    Strict route <span className="text-gray-600">(block traffic that would bypass the tunnel)</span>
+                                </label>
+                            </div>
+                        </div>
+                    </div>
                 </div>
 
                 
+                <div className="mb-8">
+                    <div className="text-sm font-medium text-white mb-3">Connect Mode</div>
+                    <div className="grid grid-cols-2 gap-3">
+                        <button onClick={() => update({ connect_mode: "profile" })} className={`p-4 rounded-xl border text-left transition-all ${!isFailover ? "bg-blue-500/20 border-blue-500/50 shadow-[0_0_15px_rgba(59,130,246,0.15)]" : "bg-black/20 border-white/5 hover:bg-white/5 opacity-70 hover:opacity-100"}`}><div className={`font-bold text-sm mb-1 ${!isFailover ? "text-blue-300" : "text-gray-400"}`}>Single Server</div><div className="text-[10px] text-gray-500 leading-tight">Use the selected profile.</div></button>
+                        <button onClick={() => update({ connect_mode: "failover" })} className={`p-4 rounded-xl border text-left transition-all ${isFailover ? "bg-blue-500/20 border-blue-500/50 shadow-[0_0_15px_rgba(59,130,246,0.15)]" : "bg-black/20 border-white/5 hover:bg-white/5 opacity-70 hover:opacity-100"}`}><div className={`font-bold text-sm mb-1 ${isFailover ? "text-blue-300" : "text-gray-400"}`}>Auto Failover</div><div className="text-[10px] text-gray-500 leading-tight">Switch to the fastest live server.</div></button>
+                    </div>
+
+                    {isFailover && (
+                        <div className="mt-4 bg-white/5 p-4 rounded-xl border border-white/5 space-y-3 animate-[fadeIn_0.3s_ease-out]">
+                            <div className="grid grid-cols-2 gap-3">
+                                <div className="flex flex-col">
+                                    <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">Subscription</label>
+                                    <select value={failover.subscription_id || ""} onChange={(e) => updateFailover({ subscription_id: e.target.value })} className="bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white outline-none focus:border-blue-500/50">
+                                        <option value="">None</option>
+                                        {subscriptions.map(sub => <option key={sub.id} value={sub.id}>{sub.name}</option>)}
+                                    </select>
+                                </div>
+                                <div className="flex flex-col">
+                                    <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">Profile Tag</label>
+                                    <input value={failover.tag || ""} onChange={(e) => updateFailover({ tag: e.target.value })} placeholder="eu" className="bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white font-mono outline-none focus:border-blue-500/50" />
+                                </div>
+                            </div>
+
+                            <div className="flex flex-col">
+                                <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">Profiles</label>
+                                <div className="max-h-32 overflow-y-auto scrollbar-thin bg-black/20 rounded-lg p-2 space-y-1">
+                                    {profiles.map(p => (
+                                        <label key={p.id} className="flex items-center gap-2 text-xs text-gray-300 cursor-pointer">
+                                            <input type="checkbox" checked={(failover.profile_ids || []).includes(p.id)} onChange={() => toggleMember(p.id)} />
+                                            <span className="truncate">{p.name}</span>
+                                        </label>
+                                    ))}
+                                </div>
+                            </div>
+
+                            <div className="grid grid-cols-4 gap-3">
+                                <div className="flex flex-col col-span-2">
+                                    <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">Test URL</label>
+                                    <input value={failover.url || ""} onChange={(e) => updateFailover({ url: e.target.value })} placeholder="https://www.gstatic.com/generate_204" className="bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white font-mono outline-none focus:border-blue-500/50" />
+                                </div>
+                                <div className="flex flex-col">
+                                    <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">Interval</label>
+                                    <input value={failover.interval || ""} onChange={(e) => updateFailover({ interval: e.target.value })} placeholder="3m" className="bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white font-mono outline-none focus:border-blue-500/50" />
+                                </div>
+                                <div className="flex flex-col">
+                                    <label className="text-[9px] font-bold text-gray-500 uppercase tracking-wider mb-1.5 ml-1">Tolerance ms</label>
+                                    <input type="number" value={failover.tolerance || ""} onChange={(e) => updateFailover({ tolerance: parseInt(e.target.value) || 0 })} placeholder="50" className="bg-black/40 border border-white/10 rounded-lg px-3 py-2 text-xs text-white font-mono outline-none focus:border-blue-500/50 [&::-webkit-inner-spin-button]:appearance-none" />
+                                </div>
+                            </div>
+                        </div>
+                    )}
+                </div>
+
+                <div className="mb-8">
+                    <div className="text-sm font-medium text-white mb-3">IPv6</div>
+                    <div className="grid grid-cols-3 gap-3">
+                        <button onClick={() => update({ ipv6: "off" })} className={`p-4 rounded-xl border text-left transition-all ${ipv6 === "off" ? "bg-sky-500/20 border-sky-500/50 shadow-[0_0_15px_rgba(14,165,233,0.15)]" : "bg-black/20 border-white/5 hover:bg-white/5 opacity-70 hover:opacity-100"}`}><div className={`font-bold text-sm mb-1 ${ipv6 === "off" ? "text-sky-300" : "text-gray-400"}`}>Off</div><div className="text-[10px] text-gray-500 leading-tight">IPv4 only, as before.</div></button>
+                        <button onClick={() => update({ ipv6: "on" })} className={`p-4 rounded-xl border text-left transition-all ${ipv6 === "on" ? "bg-sky-500/20 border-sky-500/50 shadow-[0_0_15px_rgba(14,165,233,0.15)]" : "bg-black/20 border-white/5 hover:bg-white/5 opacity-70 hover:opacity-100"}`}><div className={`font-bold text-sm mb-1 ${ipv6 === "on" ? "text-sky-300" : "text-gray-400"}`}>Tunnel</div><div className="text-[10px] text-gray-500 leading-tight">Send IPv6 through the VPN too.</div></button>
+                        <button onClick={() => update({ ipv6: "block" })} className={`p-4 rounded-xl border text-left transition-all ${ipv6 === "block" ? "bg-sky-500/20 border-sky-500/50 shadow-[0_0_15px_rgba(14,165,233,0.15)]" : "bg-black/20 border-white/5 hover:bg-white/5 opacity-70 hover:opacity-100"}`}><div className={`font-bold text-sm mb-1 ${ipv6 === "block" ? "text-sky-300" : "text-gray-400"}`}>Block</div><div className="text-[10px] text-gray-500 leading-tight">Drop IPv6 so nothing leaks.</div></button>
+                    </div>
+                </div>
+
+                <div className="mb-8">
+                    <div className="text-sm font-medium text-white mb-1">DNS</div>
+                    <div className="text-[10px] text-gray-500 mb-3">Resolvers used while connected. The system resolver always handles bypassed domains.</div>
+                    <DnsEditor dns={settings.dns || new main.DNSSettings()} onChange={(dns) => update({ dns })} />
+                </div>
+
                 <div className="mb-8">
                     <div className="text-sm font-medium text-white mb-3">Startup</div>
                     <div
@@ -70,6 +218,21 @@ export const SettingsView: React.FC<Props> = ({ settings, onUpdate, hasChanges,
                     </div>
                 </div>
 
+                <div className="mb-8">
+                    <div className="text-sm font-medium text-white mb-1">Config Overlay</div>
+                    <div className="text-[10px] text-gray-500 mb-3">JSON Merge Patch object or JSON Patch array applied to the generated sing-box config.</div>
+                    <textarea
+                        value={overlay}
+                        onChange={(e) => setOverlay(e.target.value)}
+                        onBlur={saveOverlay}
+                        spellCheck={false}
+                        rows={5}
+                        placeholder={'{ "log": { "level": "debug" } }'}
+                        className={`w-full bg-black/40 border rounded-xl p-3 text-[11px] font-mono text-gray-300 outline-none focus:bg-black/60 transition-all resize-y ${overlayError ? "border-red-500/50" : "border-white/10 focus:border-purple-500/50"}`}
+                    />
+                    {overlayError && <div className="text-[10px] text-red-400 mt-2">{overlayError}</div>}
+                </div>
+
                 <RestartBanner visible={isRunning && hasChanges} onRestart={onRestart} />
             </div>
         </div>
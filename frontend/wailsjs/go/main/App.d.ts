@@ -17,20 +17,34 @@ export function DisableAutostart():Promise<void>;
 
 export function EnableAutostart():Promise<void>;
 
+export function ExportProfiles(arg1:Array<string>):Promise<string>;
+
 export function GetLogs():Promise<Array<string>>;
 
+export function GetProfileQR(arg1:string):Promise<string>;
+
 export function GetProfiles():Promise<Array<main.Profile>>;
 
+export function GetRegions():Promise<Array<main.Region>>;
+
+export function GetRuleSetStatus():Promise<Array<main.RuleSetStatus>>;
+
 export function GetRunningProcesses():Promise<Array<string>>;
 
 export function GetRunningState():Promise<boolean>;
 
 export function GetSettings():Promise<main.Settings>;
 
+export function GetShareLink(arg1:string):Promise<string>;
+
 export function GetSubscriptions():Promise<Array<main.Subscription>>;
 
+export function ImportQR(arg1:string):Promise<string>;
+
 export function ImportSubscription(arg1:string):Promise<string>;
 
+export function ImportWireGuard(arg1:string):Promise<string>;
+
 export function LoadProfiles():Promise<Array<main.Profile>>;
 
 export function LoadSettings():Promise<main.Settings>;
@@ -39,22 +53,46 @@ export function LoadSubscriptions():Promise<Array<main.Subscription>>;
 
 export function OpenUrl(arg1:string):Promise<void>;
 
+export function PreviewConfig(arg1:string):Promise<main.ConfigPreview>;
+
+export function QuicPing(arg1:string):Promise<number>;
+
 export function SaveProfiles():Promise<void>;
 
 export function SaveSettings(arg1:main.Settings):Promise<string>;
 
 export function SaveSubscriptions():Promise<void>;
 
+export function SetProfileDetour(arg1:string,arg2:string):Promise<string>;
+
+export function SetProfileOptions(arg1:string,arg2:main.ProfileOptions):Promise<string>;
+
+export function SetProfileTags(arg1:string,arg2:Array<string>):Promise<string>;
+
 export function SetupTray(arg1:context.Context):Promise<void>;
 
+export function StartFailover():Promise<string>;
+
 export function StartVless(arg1:string):Promise<string>;
 
 export function StopVless():Promise<string>;
 
+export function SwitchProfile(arg1:string):Promise<string>;
+
 export function TcpPing(arg1:string):Promise<number>;
 
 export function UpdateProfile(arg1:string,arg2:string,arg3:string):Promise<string>;
 
+export function UpdateRuleSets():Promise<string>;
+
 export function UpdateSubscription(arg1:string):Promise<string>;
 
 export function UrlTest(arg1:string):Promise<number>;
+
+export function ValidateConfigOverlay(arg1:string):Promise<string>;
+
+export function ValidateDNS(arg1:main.DNSSettings):Promise<string>;
+
+export function ValidateProfileKey(arg1:string):Promise<Array<main.FieldError>>;
+
+export function ValidateUserRule(arg1:main.UserRule):Promise<string>;
@@ -30,14 +30,30 @@ export function EnableAutostart() {
   return window['go']['main']['App']['EnableAutostart']();
 }
 
+export function ExportProfiles(arg1) {
+  return window['go']['main']['App']['ExportProfiles'](arg1);
+}
+
 export function GetLogs() {
   return window['go']['main']['App']['GetLogs']();
 }
 
+export function GetProfileQR(arg1) {
+  return window['go']['main']['App']['GetProfileQR'](arg1);
+}
+
 export function GetProfiles() {
   return window['go']['main']['App']['GetProfiles']();
 }
 
+export function GetRegions() {
+  return window['go']['main']['App']['GetRegions']();
+}
+
+export function GetRuleSetStatus() {
+  return window['go']['main']['App']['GetRuleSetStatus']();
+}
+
 export function GetRunningProcesses() {
   return window['go']['main']['App']['GetRunningProcesses']();
 }
@@ -50,14 +66,26 @@ export function GetSettings() {
   return window['go']['main']['App']['GetSettings']();
 }
 
+export function GetShareLink(arg1) {
+  return window['go']['main']['App']['GetShareLink'](arg1);
+}
+
 export function GetSubscriptions() {
   return window['go']['main']['App']['GetSubscriptions']();
 }
 
+export function ImportQR(arg1) {
+  return window['go']['main']['App']['ImportQR'](arg1);
+}
+
 export function ImportSubscription(arg1) {
   return window['go']['main']['App']['ImportSubscription'](arg1);
 }
 
+export function ImportWireGuard(arg1) {
+  return window['go']['main']['App']['ImportWireGuard'](arg1);
+}
+
 export function LoadProfiles() {
   return window['go']['main']['App']['LoadProfiles']();
 }
@@ -74,6 +102,14 @@ export function OpenUrl(arg1) {
   return window['go']['main']['App']['OpenUrl'](arg1);
 }
 
+export function PreviewConfig(arg1) {
+  return window['go']['main']['App']['PreviewConfig'](arg1);
+}
+
+export function QuicPing(arg1) {
+  return window['go']['main']['App']['QuicPing'](arg1);
+}
+
 export function SaveProfiles() {
   return window['go']['main']['App']['SaveProfiles']();
 }
@@ -86,10 +122,26 @@ export function SaveSubscriptions() {
   return window['go']['main']['App']['SaveSubscriptions']();
 }
 
+export function SetProfileDetour(arg1, arg2) {
+  return window['go']['main']['App']['SetProfileDetour'](arg1, arg2);
+}
+
+export function SetProfileOptions(arg1, arg2) {
+  return window['go']['main']['App']['SetProfileOptions'](arg1, arg2);
+}
+
+export function SetProfileTags(arg1, arg2) {
+  return window['go']['main']['App']['SetProfileTags'](arg1, arg2);
+}
+
 export function SetupTray(arg1) {
   return window['go']['main']['App']['SetupTray'](arg1);
 }
 
+export function StartFailover() {
+  return window['go']['main']['App']['StartFailover']();
+}
+
 export function StartVless(arg1) {
   return window['go']['main']['App']['StartVless'](arg1);
 }
@@ -98,6 +150,10 @@ export function StopVless() {
   return window['go']['main']['App']['StopVless']();
 }
 
+export function SwitchProfile(arg1) {
+  return window['go']['main']['App']['SwitchProfile'](arg1);
+}
+
 export function TcpPing(arg1) {
   return window['go']['main']['App']['TcpPing'](arg1);
 }
@@ -106,6 +162,10 @@ export function UpdateProfile(arg1, arg2, arg3) {
   return window['go']['main']['App']['UpdateProfile'](arg1, arg2, arg3);
 }
 
+export function UpdateRuleSets() {
+  return window['go']['main']['App']['UpdateRuleSets']();
+}
+
 export function UpdateSubscription(arg1) {
   return window['go']['main']['App']['UpdateSubscription'](arg1);
 }
@@ -113,3 +173,19 @@ export function UpdateSubscription(arg1) {
 export function UrlTest(arg1) {
   return window['go']['main']['App']['UrlTest'](arg1);
 }
+
+export function ValidateConfigOverlay(arg1) {
+  return window['go']['main']['App']['ValidateConfigOverlay'](arg1);
+}
+
+export function ValidateDNS(arg1) {
+  return window['go']['main']['App']['ValidateDNS'](arg1);
+}
+
+export function ValidateProfileKey(arg1) {
+  return window['go']['main']['App']['ValidateProfileKey'](arg1);
+}
+
+export function ValidateUserRule(arg1) {
+  return window['go']['main']['App']['ValidateUserRule'](arg1);
+}
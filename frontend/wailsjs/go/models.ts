@@ -22,12 +22,346 @@ export namespace main {
 	        this.body = source["body"];
 	    }
 	}
+	export class Credentials {
+	    uuid?: string;
+	    password?: string;
+	    method?: string;
+	    alter_id?: number;
+	    flow?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Credentials(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.uuid = source["uuid"];
+	        this.password = source["password"];
+	        this.method = source["method"];
+	        this.alter_id = source["alter_id"];
+	        this.flow = source["flow"];
+	    }
+	}
+	export class FragmentOptions {
+	    fragment?: boolean;
+	    record_fragment?: boolean;
+	    fallback_delay?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FragmentOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fragment = source["fragment"];
+	        this.record_fragment = source["record_fragment"];
+	        this.fallback_delay = source["fallback_delay"];
+	    }
+	}
+	export class TLSOptions {
+	    reality?: boolean;
+	    server_name?: string;
+	    insecure?: boolean;
+	    alpn?: string[];
+	    fingerprint?: string;
+	    public_key?: string;
+	    short_id?: string;
+	    disable_sni?: boolean;
+	    ech?: boolean;
+	    ech_config?: string;
+	    ech_query_server_name?: string;
+	    fragment?: FragmentOptions;
+	
+	    static createFrom(source: any = {}) {
+	        return new TLSOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.reality = source["reality"];
+	        this.server_name = source["server_name"];
+	        this.insecure = source["insecure"];
+	        this.alpn = source["alpn"];
+	        this.fingerprint = source["fingerprint"];
+	        this.public_key = source["public_key"];
+	        this.short_id = source["short_id"];
+	        this.disable_sni = source["disable_sni"];
+	        this.ech = source["ech"];
+	        this.ech_config = source["ech_config"];
+	        this.ech_query_server_name = source["ech_query_server_name"];
+	        this.fragment = this.convertValues(source["fragment"], FragmentOptions);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TransportOptions {
+	    type: string;
+	    path?: string;
+	    host?: string;
+	    service_name?: string;
+	    max_early_data?: number;
+	    early_data_header?: string;
+	    mode?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TransportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.path = source["path"];
+	        this.host = source["host"];
+	        this.service_name = source["service_name"];
+	        this.max_early_data = source["max_early_data"];
+	        this.early_data_header = source["early_data_header"];
+	        this.mode = source["mode"];
+	    }
+	}
+	export class MultiplexOptions {
+	    enabled: boolean;
+	    protocol?: string;
+	    max_connections?: number;
+	    min_streams?: number;
+	    max_streams?: number;
+	    padding?: boolean;
+	    brutal_up_mbps?: number;
+	    brutal_down_mbps?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MultiplexOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.protocol = source["protocol"];
+	        this.max_connections = source["max_connections"];
+	        this.min_streams = source["min_streams"];
+	        this.max_streams = source["max_streams"];
+	        this.padding = source["padding"];
+	        this.brutal_up_mbps = source["brutal_up_mbps"];
+	        this.brutal_down_mbps = source["brutal_down_mbps"];
+	    }
+	}
+	export class ShadowsocksOptions {
+	    plugin?: string;
+	    plugin_opts?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ShadowsocksOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.plugin = source["plugin"];
+	        this.plugin_opts = source["plugin_opts"];
+	    }
+	}
+	export class Hysteria2Options {
+	    ports?: string[];
+	    obfs_type?: string;
+	    obfs_password?: string;
+	    up_mbps?: number;
+	    down_mbps?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Hysteria2Options(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ports = source["ports"];
+	        this.obfs_type = source["obfs_type"];
+	        this.obfs_password = source["obfs_password"];
+	        this.up_mbps = source["up_mbps"];
+	        this.down_mbps = source["down_mbps"];
+	    }
+	}
+	export class TUICOptions {
+	    congestion_control: string;
+	    udp_relay_mode: string;
+	    zero_rtt?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TUICOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.congestion_control = source["congestion_control"];
+	        this.udp_relay_mode = source["udp_relay_mode"];
+	        this.zero_rtt = source["zero_rtt"];
+	    }
+	}
+	export class WireGuardPeer {
+	    server: string;
+	    port: number;
+	    public_key: string;
+	    preshared_key?: string;
+	    allowed_ips: string[];
+	    keepalive?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WireGuardPeer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.server = source["server"];
+	        this.port = source["port"];
+	        this.public_key = source["public_key"];
+	        this.preshared_key = source["preshared_key"];
+	        this.allowed_ips = source["allowed_ips"];
+	        this.keepalive = source["keepalive"];
+	    }
+	}
+	export class WireGuardOptions {
+	    private_key: string;
+	    addresses: string[];
+	    mtu?: number;
+	    peers: WireGuardPeer[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WireGuardOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.private_key = source["private_key"];
+	        this.addresses = source["addresses"];
+	        this.mtu = source["mtu"];
+	        this.peers = this.convertValues(source["peers"], WireGuardPeer);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Outbound {
+	    protocol: string;
+	    server: string;
+	    port: number;
+	    credentials: Credentials;
+	    tls?: TLSOptions;
+	    transport?: TransportOptions;
+	    multiplex?: MultiplexOptions;
+	    shadowsocks?: ShadowsocksOptions;
+	    hysteria2?: Hysteria2Options;
+	    tuic?: TUICOptions;
+	    wireguard?: WireGuardOptions;
+	    raw?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Outbound(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.protocol = source["protocol"];
+	        this.server = source["server"];
+	        this.port = source["port"];
+	        this.credentials = this.convertValues(source["credentials"], Credentials);
+	        this.tls = this.convertValues(source["tls"], TLSOptions);
+	        this.transport = this.convertValues(source["transport"], TransportOptions);
+	        this.multiplex = this.convertValues(source["multiplex"], MultiplexOptions);
+	        this.shadowsocks = this.convertValues(source["shadowsocks"], ShadowsocksOptions);
+	        this.hysteria2 = this.convertValues(source["hysteria2"], Hysteria2Options);
+	        this.tuic = this.convertValues(source["tuic"], TUICOptions);
+	        this.wireguard = this.convertValues(source["wireguard"], WireGuardOptions);
+	        this.raw = source["raw"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProfileOptions {
+	    multiplex?: MultiplexOptions;
+	    fragment?: FragmentOptions;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfileOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.multiplex = this.convertValues(source["multiplex"], MultiplexOptions);
+	        this.fragment = this.convertValues(source["fragment"], FragmentOptions);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Profile {
 	    id: string;
 	    name: string;
 	    key: string;
+	    outbound?: Outbound;
+	    options?: ProfileOptions;
 	    subscription_id: string;
 	    created_at: number;
+	    tags?: string[];
+	    detour_id?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Profile(source);
@@ -38,14 +372,95 @@ export namespace main {
 	        this.id = source["id"];
 	        this.name = source["name"];
 	        this.key = source["key"];
+	        this.outbound = this.convertValues(source["outbound"], Outbound);
+	        this.options = this.convertValues(source["options"], ProfileOptions);
 	        this.subscription_id = source["subscription_id"];
 	        this.created_at = source["created_at"];
+	        this.tags = source["tags"];
+	        this.detour_id = source["detour_id"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Region {
+	    code: string;
+	    name: string;
+	    domains: string[];
+	    dns?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Region(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.name = source["name"];
+	        this.domains = source["domains"];
+	        this.dns = source["dns"];
+	    }
+	}
+	export class RuleSetStatus {
+	    tag: string;
+	    url: string;
+	    cached: boolean;
+	    embedded: boolean;
+	    version: number;
+	    updated_at: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleSetStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag = source["tag"];
+	        this.url = source["url"];
+	        this.cached = source["cached"];
+	        this.embedded = source["embedded"];
+	        this.version = source["version"];
+	        this.updated_at = source["updated_at"];
+	    }
+	}
+	export class RuleCondition {
+	    type: string;
+	    values: string[];
+	    invert?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleCondition(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.values = source["values"];
+	        this.invert = source["invert"];
 	    }
 	}
 	export class UserRule {
 	    id: string;
 	    type: string;
-	    value: string;
+	    values?: string[];
+	    value?: string;
+	    mode?: string;
+	    conditions?: RuleCondition[];
 	    outbound: string;
 	
 	    static createFrom(source: any = {}) {
@@ -56,18 +471,209 @@ export namespace main {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
 	        this.type = source["type"];
+	        this.values = source["values"];
 	        this.value = source["value"];
+	        this.mode = source["mode"];
+	        this.conditions = this.convertValues(source["conditions"], RuleCondition);
 	        this.outbound = source["outbound"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FailoverGroup {
+	    profile_ids?: string[];
+	    subscription_id?: string;
+	    tag?: string;
+	    url?: string;
+	    interval?: string;
+	    tolerance?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FailoverGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.profile_ids = source["profile_ids"];
+	        this.subscription_id = source["subscription_id"];
+	        this.tag = source["tag"];
+	        this.url = source["url"];
+	        this.interval = source["interval"];
+	        this.tolerance = source["tolerance"];
+	    }
+	}
+	export class DNSServer {
+	    tag: string;
+	    type: string;
+	    address: string;
+	    detour?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DNSServer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag = source["tag"];
+	        this.type = source["type"];
+	        this.address = source["address"];
+	        this.detour = source["detour"];
+	    }
+	}
+	export class DNSRule {
+	    domains: string[];
+	    server: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DNSRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.domains = source["domains"];
+	        this.server = source["server"];
+	    }
+	}
+	export class FakeIPSettings {
+	    enabled: boolean;
+	    inet4_range?: string;
+	    inet6_range?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FakeIPSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.inet4_range = source["inet4_range"];
+	        this.inet6_range = source["inet6_range"];
+	    }
+	}
+	export class DNSSettings {
+	    servers: DNSServer[];
+	    rules?: DNSRule[];
+	    final?: string;
+	    strategy?: string;
+	    disable_cache?: boolean;
+	    disable_expire?: boolean;
+	    independent_cache?: boolean;
+	    cache_capacity?: number;
+	    fake_ip: FakeIPSettings;
+	
+	    static createFrom(source: any = {}) {
+	        return new DNSSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.servers = this.convertValues(source["servers"], DNSServer);
+	        this.rules = this.convertValues(source["rules"], DNSRule);
+	        this.final = source["final"];
+	        this.strategy = source["strategy"];
+	        this.disable_cache = source["disable_cache"];
+	        this.disable_expire = source["disable_expire"];
+	        this.independent_cache = source["independent_cache"];
+	        this.cache_capacity = source["cache_capacity"];
+	        this.fake_ip = this.convertValues(source["fake_ip"], FakeIPSettings);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TunSettings {
+	    mtu?: number;
+	    stack?: string;
+	    strict_route?: boolean;
+	    interface_name?: string;
+	    route_exclude_address?: string[];
+	    include_uid?: number[];
+	    exclude_uid?: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TunSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mtu = source["mtu"];
+	        this.stack = source["stack"];
+	        this.strict_route = source["strict_route"];
+	        this.interface_name = source["interface_name"];
+	        this.route_exclude_address = source["route_exclude_address"];
+	        this.include_uid = source["include_uid"];
+	        this.exclude_uid = source["exclude_uid"];
+	    }
+	}
+	export class RuleSet {
+	    tag: string;
+	    type: string;
+	    format?: string;
+	    url?: string;
+	    path?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleSet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag = source["tag"];
+	        this.type = source["type"];
+	        this.format = source["format"];
+	        this.url = source["url"];
+	        this.path = source["path"];
+	    }
 	}
 	export class Settings {
 	    routing_mode: string;
 	    run_mode: string;
 	    mixed_port: number;
 	    user_rules: UserRule[];
-	    ru_domains: string[];
+	    ru_domains?: string[];
 	    auto_connect: boolean;
 	    last_profile_id: string;
+	    config_overlay: string;
+	    connect_mode: string;
+	    failover: FailoverGroup;
+	    dns: DNSSettings;
+	    ipv6: string;
+	    tun: TunSettings;
+	    rule_sets: RuleSet[];
+	    regions: string[];
+	    direct_domains: string[];
+	    region_dns?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new Settings(source);
@@ -82,6 +688,16 @@ export namespace main {
 	        this.ru_domains = source["ru_domains"];
 	        this.auto_connect = source["auto_connect"];
 	        this.last_profile_id = source["last_profile_id"];
+	        this.config_overlay = source["config_overlay"];
+	        this.connect_mode = source["connect_mode"];
+	        this.failover = this.convertValues(source["failover"], FailoverGroup);
+	        this.dns = this.convertValues(source["dns"], DNSSettings);
+	        this.ipv6 = source["ipv6"];
+	        this.tun = this.convertValues(source["tun"], TunSettings);
+	        this.rule_sets = this.convertValues(source["rule_sets"], RuleSet);
+	        this.regions = source["regions"];
+	        this.direct_domains = source["direct_domains"];
+	        this.region_dns = source["region_dns"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -120,6 +736,90 @@ export namespace main {
 	        this.updated_at = source["updated_at"];
 	    }
 	}
+	export class ConfigChange {
+	    path: string;
+	    kind: string;
+	    old?: any;
+	    new?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.kind = source["kind"];
+	        this.old = source["old"];
+	        this.new = source["new"];
+	    }
+	}
+	export class ConfigCheck {
+	    ok: boolean;
+	    output: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ok = source["ok"];
+	        this.output = source["output"];
+	    }
+	}
+	export class ConfigPreview {
+	    config: string;
+	    has_baseline: boolean;
+	    diff: ConfigChange[];
+	    check?: ConfigCheck;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.config = source["config"];
+	        this.has_baseline = source["has_baseline"];
+	        this.diff = this.convertValues(source["diff"], ConfigChange);
+	        this.check = this.convertValues(source["check"], ConfigCheck);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FieldError {
+	    field: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.message = source["message"];
+	    }
+	}
 
 }
 
@@ -50,7 +50,7 @@ func (a *App) onTrayReady(ctx context.Context) {
 				if a.GetRunningState() {
 					a.StopVless()
 				} else {
-					if a.Settings.LastProfileID != "" {
+					if a.settingsSnapshot().LastProfileID != "" {
 						a.connectLastProfile()
 					} else {
 						wailsRuntime.WindowShow(ctx)